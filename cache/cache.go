@@ -51,6 +51,13 @@ type Cache interface {
 	Dec(key string, value uint64) (int64, error)
 }
 
+// IsRetryable reports whether a cache error is worth retrying.
+// Cache misses and failed conditional writes are not, as they are
+// answers rather than failures.
+func IsRetryable(err error) bool {
+	return !errors.Is(err, ErrCacheMiss) && !errors.Is(err, ErrNotStored)
+}
+
 // WithCache sets Cache in the context.
 func WithCache(ctx context.Context, cache Cache) context.Context {
 	return context.WithValue(ctx, ctxKey, cache)
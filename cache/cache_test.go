@@ -2,6 +2,8 @@ package cache_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -10,6 +12,12 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+func TestIsRetryable(t *testing.T) {
+	assert.False(t, cache.IsRetryable(cache.ErrCacheMiss))
+	assert.False(t, cache.IsRetryable(fmt.Errorf("add: %w", cache.ErrNotStored)))
+	assert.True(t, cache.IsRetryable(errors.New("test error")))
+}
+
 func TestGet(t *testing.T) {
 	m := new(MockCache)
 	m.On("Get", "test").Return(&cache.Item{})
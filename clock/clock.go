@@ -0,0 +1,92 @@
+// Package clock abstracts time, so code depending on it can be tested
+// deterministically with a Fake clock.
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+// Real is the Clock of the system time.
+var Real Clock = realClock{}
+
+// Clock tells the time and creates timers.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// Since returns the time elapsed since t.
+	Since(t time.Time) time.Duration
+
+	// NewTimer creates a Timer firing once after the duration.
+	NewTimer(d time.Duration) Timer
+
+	// NewTicker creates a Ticker firing every period.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a single event, like time.Timer.
+type Timer interface {
+	// C returns the channel the time is delivered on.
+	C() <-chan time.Time
+
+	// Stop prevents the Timer from firing. It returns false if the
+	// Timer already fired or was stopped.
+	Stop() bool
+}
+
+// Ticker delivers ticks at intervals, like time.Ticker.
+type Ticker interface {
+	// C returns the channel the ticks are delivered on.
+	C() <-chan time.Time
+
+	// Stop turns off the Ticker.
+	Stop()
+}
+
+// Sleep pauses for the duration on the clock, or until the context is done.
+func Sleep(ctx context.Context, c Clock, d time.Duration) error {
+	t := c.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C():
+		return nil
+	}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
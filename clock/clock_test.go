@@ -0,0 +1,49 @@
+package clock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msales/pkg/v5/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReal(t *testing.T) {
+	c := clock.Real
+
+	start := c.Now()
+	timer := c.NewTimer(time.Millisecond)
+	<-timer.C()
+
+	assert.True(t, c.Since(start) >= time.Millisecond)
+	assert.False(t, timer.Stop())
+
+	ticker := c.NewTicker(time.Millisecond)
+	<-ticker.C()
+	<-ticker.C()
+	ticker.Stop()
+}
+
+func TestSleep(t *testing.T) {
+	c := clock.NewFake(time.Now())
+
+	done := make(chan error)
+	go func() {
+		done <- clock.Sleep(context.Background(), c, time.Minute)
+	}()
+
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+
+	assert.NoError(t, <-done)
+}
+
+func TestSleep_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := clock.Sleep(ctx, clock.NewFake(time.Now()), time.Minute)
+
+	assert.Equal(t, context.Canceled, err)
+}
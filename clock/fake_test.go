@@ -0,0 +1,103 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/msales/pkg/v5/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake_Now(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := clock.NewFake(start)
+
+	c.Advance(time.Hour)
+
+	assert.Equal(t, start.Add(time.Hour), c.Now())
+	assert.Equal(t, time.Hour, c.Since(start))
+}
+
+func TestFake_Timer(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := clock.NewFake(start)
+
+	timer := c.NewTimer(time.Minute)
+
+	c.Advance(59 * time.Second)
+	assertNotFired(t, timer.C())
+
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Minute), <-timer.C())
+	assert.False(t, timer.Stop())
+}
+
+func TestFake_TimerStop(t *testing.T) {
+	c := clock.NewFake(time.Now())
+
+	timer := c.NewTimer(time.Minute)
+
+	assert.True(t, timer.Stop())
+	c.Advance(time.Minute)
+	assertNotFired(t, timer.C())
+}
+
+func TestFake_TimerZero(t *testing.T) {
+	c := clock.NewFake(time.Now())
+
+	timer := c.NewTimer(0)
+
+	assert.Equal(t, c.Now(), <-timer.C())
+}
+
+func TestFake_Ticker(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := clock.NewFake(start)
+
+	ticker := c.NewTicker(time.Minute)
+
+	c.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute), <-ticker.C())
+
+	// Ticks are dropped for slow receivers.
+	c.Advance(3 * time.Minute)
+	assert.Equal(t, start.Add(2*time.Minute), <-ticker.C())
+	assertNotFired(t, ticker.C())
+
+	ticker.Stop()
+	c.Advance(time.Minute)
+	assertNotFired(t, ticker.C())
+}
+
+func TestFake_NewTickerPanics(t *testing.T) {
+	c := clock.NewFake(time.Now())
+
+	assert.Panics(t, func() {
+		c.NewTicker(0)
+	})
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	c := clock.NewFake(time.Now())
+
+	done := make(chan struct{})
+	go func() {
+		c.BlockUntil(2)
+		close(done)
+	}()
+
+	c.NewTimer(time.Minute)
+	c.NewTicker(time.Minute)
+
+	<-done
+}
+
+func assertNotFired(t *testing.T, ch <-chan time.Time) {
+	t.Helper()
+
+	select {
+	case <-ch:
+		assert.Fail(t, "unexpected fire")
+	default:
+	}
+}
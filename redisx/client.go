@@ -1,9 +1,121 @@
 package redisx
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sync"
+
 	"github.com/go-redis/redis"
 )
 
+var (
+	// ErrInvalidCursor means that a scan cursor token could not be decoded.
+	ErrInvalidCursor = errors.New("redisx: invalid cursor")
+
+	// ErrUnsupportedClient means that the given value is not a supported
+	// redis client.
+	ErrUnsupportedClient = errors.New("redisx: unsupported client")
+)
+
+// Client represents a redis client of any topology that is able to run
+// commands on each of its nodes.
+type Client interface {
+	redis.UniversalClient
+
+	// ForEachNode concurrently calls fn for every node holding data: each
+	// master of a cluster, each shard of a ring or the client itself.
+	ForEachNode(fn func(client *redis.Client) error) error
+}
+
+type client struct {
+	redis.UniversalClient
+
+	forEach func(fn func(client *redis.Client) error) error
+}
+
+// NewClient returns a Client for a single node redis client.
+func NewClient(c *redis.Client) Client {
+	return &client{
+		UniversalClient: c,
+		forEach: func(fn func(client *redis.Client) error) error {
+			return fn(c)
+		},
+	}
+}
+
+// NewClusterClient returns a Client for a redis cluster client.
+func NewClusterClient(c *redis.ClusterClient) Client {
+	return &client{
+		UniversalClient: c,
+		forEach:         c.ForEachMaster,
+	}
+}
+
+// NewRingClient returns a Client for a redis ring client.
+func NewRingClient(c *redis.Ring) Client {
+	return &client{
+		UniversalClient: c,
+		forEach:         c.ForEachShard,
+	}
+}
+
+// NewSentinelClient returns a Client for the master monitored by sentinel.
+func NewSentinelClient(opt *redis.FailoverOptions) Client {
+	return NewClient(redis.NewFailoverClient(opt))
+}
+
+// Wrap returns a Client for any of the go-redis client types.
+func Wrap(c interface{}) (Client, error) {
+	switch c := c.(type) {
+	case Client:
+		return c, nil
+	case *redis.Client:
+		return NewClient(c), nil
+	case *redis.ClusterClient:
+		return NewClusterClient(c), nil
+	case *redis.Ring:
+		return NewRingClient(c), nil
+	default:
+		return nil, ErrUnsupportedClient
+	}
+}
+
+// ForEachNode calls fn for every node holding data.
+func (c *client) ForEachNode(fn func(client *redis.Client) error) error {
+	return c.forEach(fn)
+}
+
+// Info returns the INFO output of every node, keyed by node address.
+func Info(c Client, section ...string) (map[string]string, error) {
+	var mu sync.Mutex
+	info := map[string]string{}
+	err := c.ForEachNode(func(client *redis.Client) error {
+		res, err := client.Info(section...).Result()
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		info[client.Options().Addr] = res
+		mu.Unlock()
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// FlushAll removes all keys from every node.
+func FlushAll(c Client) error {
+	return c.ForEachNode(func(client *redis.Client) error {
+		return client.FlushAll().Err()
+	})
+}
+
 // ScanIterator represents a generic redis scan iterator that works on both
 // redis Client and ClusterClient
 type ScanIterator interface {
@@ -24,55 +136,94 @@ type ScanCmd interface {
 
 // NewScanIterator returns a scan operator regarding redis client type
 func NewScanIterator(c interface{}, cursor uint64, match string, count int64) (ScanIterator, error) {
-	_, isCluster := c.(ClusterClient)
+	var forEach func(fn func(client *redis.Client) error) error
+	switch cc := c.(type) {
+	case Client:
+		forEach = cc.ForEachNode
+	case ClusterClient:
+		forEach = cc.ForEachMaster
+	case redis.Cmdable:
+		return cc.Scan(cursor, match, count).Iterator(), nil
+	default:
+		return nil, ErrUnsupportedClient
+	}
 
-	if !isCluster {
-		return c.(redis.Cmdable).Scan(cursor, match, count).Iterator(), nil
+	nodes, err := collectNodes(forEach)
+	if err != nil {
+		return nil, err
 	}
 
-	iterators := make([]ScanIterator, 0)
-	err := c.(ClusterClient).ForEachMaster(func(client *redis.Client) error {
-		iterators = append(iterators, client.Scan(cursor, match, count).Iterator())
+	o := &keysOptions{scanCount: count}
+	cs := &ClusterScanIterator{}
+	for _, node := range nodes {
+		cs.scanners = append(cs.scanners, newNodeScanner(node, cursor, match, o))
+	}
 
-		return nil
-	})
+	return cs, nil
+}
 
+// ResumeScanIterator returns a scan iterator over all masters that continues
+// from a token previously returned by ClusterScanIterator.Cursor. An empty
+// token starts a new scan. Only the WithScanCount and WithType options apply.
+//
+// Masters missing from the token, e.g. after a failover, are scanned from
+// the beginning and unknown nodes in the token are ignored, so keys may be
+// returned more than once but are never skipped.
+func ResumeScanIterator(c Client, token string, match string, opts ...KeysOptionsFunc) (*ClusterScanIterator, error) {
+	cursors, err := decodeCursor(token)
 	if err != nil {
 		return nil, err
 	}
 
-	return &ClusterScanIterator{
-		curr:      0,
-		iterators: iterators,
-	}, nil
+	nodes, err := collectNodes(c.ForEachNode)
+	if err != nil {
+		return nil, err
+	}
+
+	o := newKeysOptions(opts)
+	cs := &ClusterScanIterator{}
+	for _, node := range nodes {
+		s := newNodeScanner(node, 0, match, o)
+		if nc, ok := cursors[s.addr]; ok {
+			s.next = nc.Cursor
+			s.done = nc.Done
+		}
+
+		cs.scanners = append(cs.scanners, s)
+	}
+
+	return cs, nil
 }
 
 // ClusterScanIterator represents redis cluster scan iterator
 type ClusterScanIterator struct {
-	iterators []ScanIterator
+	scanners []*nodeScanner
 
 	curr int
 }
 
 // Val returns current value pointed by the iterator
 func (cs *ClusterScanIterator) Val() string {
-	return cs.getCurrentIterator().Val()
+	if cs.curr >= len(cs.scanners) {
+		return ""
+	}
+
+	return cs.scanners[cs.curr].val()
 }
 
 // Next returns true if there is at least one more value in iterator
 func (cs *ClusterScanIterator) Next() bool {
-	i := cs.getCurrentIterator()
-
-	if i.Next() {
-		return true
-	}
-
-	for cs.nextIterator() {
-		i = cs.getCurrentIterator()
-
-		if i.Next() {
+	for cs.curr < len(cs.scanners) {
+		s := cs.scanners[cs.curr]
+		if s.nextKey() {
 			return true
 		}
+
+		if s.err != nil {
+			return false
+		}
+
+		cs.curr++
 	}
 
 	return false
@@ -80,15 +231,133 @@ func (cs *ClusterScanIterator) Next() bool {
 
 // Err returns an error for iterator
 func (cs *ClusterScanIterator) Err() error {
-	return cs.getCurrentIterator().Err()
+	if cs.curr >= len(cs.scanners) {
+		return nil
+	}
+
+	return cs.scanners[cs.curr].err
+}
+
+// Cursor returns a serialisable token of the per-node cursors that can be
+// passed to ResumeScanIterator. The token covers every value up to and
+// including Val, but resuming may return some of those values again.
+func (cs *ClusterScanIterator) Cursor() string {
+	cursors := make([]nodeCursor, 0, len(cs.scanners))
+	for _, s := range cs.scanners {
+		cursors = append(cursors, s.cursorState())
+	}
+
+	b, _ := json.Marshal(cursors)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+type nodeCursor struct {
+	Addr   string `json:"a"`
+	Cursor uint64 `json:"c,omitempty"`
+	Done   bool   `json:"d,omitempty"`
+}
+
+func decodeCursor(token string) (map[string]nodeCursor, error) {
+	cursors := map[string]nodeCursor{}
+	if token == "" {
+		return cursors, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var list []nodeCursor
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	for _, nc := range list {
+		cursors[nc.Addr] = nc
+	}
+
+	return cursors, nil
+}
+
+// nodeScanner scans a single node page by page, keeping track of the cursors
+// so that the scan can be resumed.
+type nodeScanner struct {
+	node  *redis.Client
+	addr  string
+	match string
+	count int64
+	typ   string
+
+	cursor  uint64
+	next    uint64
+	keys    []string
+	pos     int
+	started bool
+	done    bool
+	err     error
 }
 
-func (cs *ClusterScanIterator) getCurrentIterator() ScanIterator {
-	return cs.iterators[0]
+func newNodeScanner(node *redis.Client, cursor uint64, match string, o *keysOptions) *nodeScanner {
+	return &nodeScanner{
+		node:  node,
+		addr:  node.Options().Addr,
+		match: match,
+		count: o.scanCount,
+		typ:   o.typ,
+		next:  cursor,
+	}
 }
 
-func (cs *ClusterScanIterator) nextIterator() bool {
-	cs.iterators = cs.iterators[1:]
+func (s *nodeScanner) val() string {
+	if s.pos == 0 || s.pos > len(s.keys) {
+		return ""
+	}
+
+	return s.keys[s.pos-1]
+}
+
+func (s *nodeScanner) nextKey() bool {
+	for {
+		if s.pos < len(s.keys) {
+			s.pos++
+			return true
+		}
 
-	return len(cs.iterators) > 0
+		if s.done || s.err != nil {
+			return false
+		}
+
+		if s.started && s.next == 0 {
+			s.done = true
+			return false
+		}
+
+		keys, next, err := scanPage(s.node, s.next, s.match, s.count, s.typ)
+		if err != nil {
+			s.err = err
+			return false
+		}
+
+		s.cursor, s.next = s.next, next
+		s.keys, s.pos = keys, 0
+		s.started = true
+	}
+}
+
+func (s *nodeScanner) cursorState() nodeCursor {
+	switch {
+	case s.done:
+		return nodeCursor{Addr: s.addr, Done: true}
+
+	case s.started && s.pos < len(s.keys):
+		return nodeCursor{Addr: s.addr, Cursor: s.cursor}
+
+	case s.started && s.next == 0:
+		return nodeCursor{Addr: s.addr, Done: true}
+
+	default:
+		return nodeCursor{Addr: s.addr, Cursor: s.next}
+	}
 }
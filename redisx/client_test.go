@@ -2,9 +2,10 @@ package redisx_test
 
 import (
 	"errors"
+	"sync"
 	"testing"
 
-	"github.com/alicebob/miniredis"
+	"github.com/alicebob/miniredis/v2"
 	"github.com/go-redis/redis"
 	"github.com/stretchr/testify/assert"
 
@@ -105,6 +106,89 @@ func TestClusterScanIterator_Val(t *testing.T) {
 
 }
 
+func TestClusterScanIterator_UnsupportedClient(t *testing.T) {
+	_, err := redisx.NewScanIterator("client", 0, "test*", 0)
+
+	assert.Equal(t, redisx.ErrUnsupportedClient, err)
+}
+
+func TestWrap(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+
+	tests := []struct {
+		name   string
+		client interface{}
+	}{
+		{
+			name:   "client",
+			client: redis.NewClient(&redis.Options{Addr: s.Addr()}),
+		},
+		{
+			name:   "cluster client",
+			client: redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{s.Addr()}}),
+		},
+		{
+			name:   "ring client",
+			client: redis.NewRing(&redis.RingOptions{Addrs: map[string]string{"shard": s.Addr()}}),
+		},
+		{
+			name:   "wrapped client",
+			client: redisx.NewClient(redis.NewClient(&redis.Options{Addr: s.Addr()})),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := redisx.Wrap(tt.client)
+			assert.NoError(t, err)
+
+			var addrs []string
+			err = c.ForEachNode(func(client *redis.Client) error {
+				addrs = append(addrs, client.Options().Addr)
+				return nil
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, []string{s.Addr()}, addrs)
+		})
+	}
+}
+
+func TestWrap_UnsupportedClient(t *testing.T) {
+	_, err := redisx.Wrap("client")
+
+	assert.Equal(t, redisx.ErrUnsupportedClient, err)
+}
+
+func TestInfo(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+
+	c := redisx.NewClient(redis.NewClient(&redis.Options{Addr: s.Addr()}))
+
+	info, err := redisx.Info(c, "clients")
+
+	assert.NoError(t, err)
+	assert.Contains(t, info[s.Addr()], "connected_clients")
+}
+
+func TestFlushAll(t *testing.T) {
+	client1 := getClient()
+	client2 := getClient()
+
+	client1.Set("test1", 1, 0)
+	client2.Set("test2", 2, 0)
+
+	err := redisx.FlushAll(newNodesClientMock(client1, client2))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), client1.DBSize().Val())
+	assert.Equal(t, int64(0), client2.DBSize().Val())
+}
+
 func getClient() *redis.Client {
 	s, err := miniredis.Run()
 	if err != nil {
@@ -135,3 +219,136 @@ type erroredClientMock struct {
 func (e *erroredClientMock) ForEachMaster(fn func(client *redis.Client) error) error {
 	return errors.New("test error")
 }
+
+// nodesClientMock is a Client whose nodes are separate redis instances.
+type nodesClientMock struct {
+	*redis.Client
+
+	Masters []*redis.Client
+}
+
+func newNodesClientMock(masters ...*redis.Client) *nodesClientMock {
+	return &nodesClientMock{
+		Client:  masters[0],
+		Masters: masters,
+	}
+}
+
+// ForEachNode calls fn concurrently for every node, like the cluster and
+// ring clients do.
+func (c *nodesClientMock) ForEachNode(fn func(client *redis.Client) error) error {
+	var wg sync.WaitGroup
+	errs := make(chan error, len(c.Masters))
+	for _, master := range c.Masters {
+		wg.Add(1)
+		go func(master *redis.Client) {
+			defer wg.Done()
+
+			if err := fn(master); err != nil {
+				errs <- err
+			}
+		}(master)
+	}
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+type erroredNodesClientMock struct {
+	*redis.Client
+}
+
+func (c *erroredNodesClientMock) ForEachNode(fn func(client *redis.Client) error) error {
+	return errors.New("test error")
+}
+
+func TestClusterScanIterator_Cursor(t *testing.T) {
+	client1 := getClient()
+	client2 := getClient()
+
+	client1.Set("test1", 1, 0)
+	client2.Set("test2", 2, 0)
+	client2.Set("test3", 3, 0)
+
+	client := newNodesClientMock(client1, client2)
+	match := "test*"
+
+	scanIterator, err := redisx.ResumeScanIterator(client, "", match)
+	assert.NoError(t, err)
+
+	// The nodes are visited in no particular order and resuming may
+	// return keys again, but never skips any.
+	assert.True(t, scanIterator.Next())
+	seen := map[string]bool{scanIterator.Val(): true}
+	token := scanIterator.Cursor()
+
+	scanIterator, err = redisx.ResumeScanIterator(client, token, match)
+	assert.NoError(t, err)
+
+	for scanIterator.Next() {
+		seen[scanIterator.Val()] = true
+	}
+	assert.NoError(t, scanIterator.Err())
+	assert.Equal(t, map[string]bool{"test1": true, "test2": true, "test3": true}, seen)
+	token = scanIterator.Cursor()
+
+	scanIterator, err = redisx.ResumeScanIterator(client, token, match)
+	assert.NoError(t, err)
+
+	assert.False(t, scanIterator.Next())
+}
+
+func TestClusterScanIterator_CursorUnknownNode(t *testing.T) {
+	client1 := getClient()
+	client1.Set("test1", 1, 0)
+	done := newNodesClientMock(client1)
+
+	scanIterator, err := redisx.ResumeScanIterator(done, "", "test*")
+	assert.NoError(t, err)
+	for scanIterator.Next() {
+	}
+	token := scanIterator.Cursor()
+
+	client2 := getClient()
+	client2.Set("test2", 2, 0)
+	client := newNodesClientMock(client1, client2)
+
+	scanIterator, err = redisx.ResumeScanIterator(client, token, "test*")
+	assert.NoError(t, err)
+
+	assert.True(t, scanIterator.Next())
+	assert.Equal(t, "test2", scanIterator.Val())
+	assert.False(t, scanIterator.Next())
+}
+
+func TestResumeScanIterator_InvalidCursor(t *testing.T) {
+	client := newNodesClientMock(getClient())
+
+	_, err := redisx.ResumeScanIterator(client, "!invalid", "test*")
+
+	assert.Equal(t, redisx.ErrInvalidCursor, err)
+}
+
+func TestResumeScanIterator_WithError(t *testing.T) {
+	_, err := redisx.ResumeScanIterator(&erroredNodesClientMock{getClient()}, "", "test*")
+
+	assert.Error(t, err)
+}
+
+func TestResumeScanIterator_WithType(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	client.Set("test1", 1, 0)
+	client.HSet("test2", "field", 2)
+
+	scanIterator, err := redisx.ResumeScanIterator(client, "", "test*", redisx.WithType("hash"))
+	assert.NoError(t, err)
+
+	assert.True(t, scanIterator.Next())
+	assert.Equal(t, "test2", scanIterator.Val())
+	assert.False(t, scanIterator.Next())
+}
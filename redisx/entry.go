@@ -0,0 +1,143 @@
+package redisx
+
+import (
+	"strconv"
+
+	"github.com/go-redis/redis"
+)
+
+// Entry represents a single element of a hash, set or sorted set.
+type Entry struct {
+	// Key is the key of the scanned hash, set or sorted set.
+	Key string
+
+	// Field is the hash field or the set member.
+	Field string
+
+	// Value is the hash value. It is empty for sets and sorted sets.
+	Value string
+
+	// Score is the sorted set score. It is zero for hashes and sets.
+	Score float64
+}
+
+// EntryScanIterator represents a scan iterator over the elements of a single
+// hash, set or sorted set.
+type EntryScanIterator interface {
+	Val() Entry
+	Next() bool
+	Err() error
+}
+
+// NewHScanIterator returns an iterator over the fields of the hash at key.
+//
+// The client may be a single node or a cluster client, in which case the
+// command is routed to the node owning the key.
+func NewHScanIterator(c redis.Cmdable, key string, cursor uint64, match string, count int64) EntryScanIterator {
+	return &entryScanIterator{
+		key:  key,
+		next: cursor,
+		step: 2,
+		scan: func(cursor uint64) ([]string, uint64, error) {
+			return c.HScan(key, cursor, match, count).Result()
+		},
+		entry: func(key string, vals []string) (Entry, error) {
+			return Entry{Key: key, Field: vals[0], Value: vals[1]}, nil
+		},
+	}
+}
+
+// NewSScanIterator returns an iterator over the members of the set at key.
+//
+// The client may be a single node or a cluster client, in which case the
+// command is routed to the node owning the key.
+func NewSScanIterator(c redis.Cmdable, key string, cursor uint64, match string, count int64) EntryScanIterator {
+	return &entryScanIterator{
+		key:  key,
+		next: cursor,
+		step: 1,
+		scan: func(cursor uint64) ([]string, uint64, error) {
+			return c.SScan(key, cursor, match, count).Result()
+		},
+		entry: func(key string, vals []string) (Entry, error) {
+			return Entry{Key: key, Field: vals[0]}, nil
+		},
+	}
+}
+
+// NewZScanIterator returns an iterator over the members and scores of the
+// sorted set at key.
+//
+// The client may be a single node or a cluster client, in which case the
+// command is routed to the node owning the key.
+func NewZScanIterator(c redis.Cmdable, key string, cursor uint64, match string, count int64) EntryScanIterator {
+	return &entryScanIterator{
+		key:  key,
+		next: cursor,
+		step: 2,
+		scan: func(cursor uint64) ([]string, uint64, error) {
+			return c.ZScan(key, cursor, match, count).Result()
+		},
+		entry: func(key string, vals []string) (Entry, error) {
+			score, err := strconv.ParseFloat(vals[1], 64)
+			if err != nil {
+				return Entry{}, err
+			}
+
+			return Entry{Key: key, Field: vals[0], Score: score}, nil
+		},
+	}
+}
+
+type entryScanIterator struct {
+	key   string
+	scan  func(cursor uint64) ([]string, uint64, error)
+	entry func(key string, vals []string) (Entry, error)
+	step  int
+
+	next    uint64
+	vals    []string
+	pos     int
+	started bool
+	val     Entry
+	err     error
+}
+
+// Val returns current value pointed by the iterator
+func (it *entryScanIterator) Val() Entry {
+	return it.val
+}
+
+// Next returns true if there is at least one more value in iterator
+func (it *entryScanIterator) Next() bool {
+	for {
+		if it.err != nil {
+			return false
+		}
+
+		if it.pos+it.step <= len(it.vals) {
+			it.val, it.err = it.entry(it.key, it.vals[it.pos:it.pos+it.step])
+			it.pos += it.step
+
+			return it.err == nil
+		}
+
+		if it.started && it.next == 0 {
+			return false
+		}
+
+		vals, next, err := it.scan(it.next)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.vals, it.pos, it.next = vals, 0, next
+		it.started = true
+	}
+}
+
+// Err returns an error for iterator
+func (it *entryScanIterator) Err() error {
+	return it.err
+}
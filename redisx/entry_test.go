@@ -0,0 +1,64 @@
+package redisx_test
+
+import (
+	"testing"
+
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/msales/pkg/v5/redisx"
+)
+
+func TestHScanIterator(t *testing.T) {
+	client := getClient()
+	client.HSet("hash", "field1", "value1")
+	client.HSet("hash", "field2", "value2")
+	client.HSet("hash", "other", "value3")
+
+	it := redisx.NewHScanIterator(client, "hash", 0, "field*", 0)
+
+	assert.True(t, it.Next())
+	assert.Equal(t, redisx.Entry{Key: "hash", Field: "field1", Value: "value1"}, it.Val())
+	assert.True(t, it.Next())
+	assert.Equal(t, redisx.Entry{Key: "hash", Field: "field2", Value: "value2"}, it.Val())
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Err())
+}
+
+func TestSScanIterator(t *testing.T) {
+	client := getClient()
+	client.SAdd("set", "member1", "member2")
+
+	it := redisx.NewSScanIterator(client, "set", 0, "", 0)
+
+	assert.True(t, it.Next())
+	assert.Equal(t, redisx.Entry{Key: "set", Field: "member1"}, it.Val())
+	assert.True(t, it.Next())
+	assert.Equal(t, redisx.Entry{Key: "set", Field: "member2"}, it.Val())
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Err())
+}
+
+func TestZScanIterator(t *testing.T) {
+	client := getClient()
+	client.ZAdd("zset", redis.Z{Score: 1.5, Member: "member1"}, redis.Z{Score: 2, Member: "member2"})
+
+	it := redisx.NewZScanIterator(client, "zset", 0, "", 0)
+
+	assert.True(t, it.Next())
+	assert.Equal(t, redisx.Entry{Key: "zset", Field: "member1", Score: 1.5}, it.Val())
+	assert.True(t, it.Next())
+	assert.Equal(t, redisx.Entry{Key: "zset", Field: "member2", Score: 2}, it.Val())
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Err())
+}
+
+func TestEntryScanIterator_WrongType(t *testing.T) {
+	client := getClient()
+	client.Set("key", "value", 0)
+
+	it := redisx.NewHScanIterator(client, "key", 0, "", 0)
+
+	assert.False(t, it.Next())
+	assert.Error(t, it.Err())
+}
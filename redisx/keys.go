@@ -0,0 +1,344 @@
+package redisx
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis"
+
+	"github.com/msales/pkg/v5/clock"
+)
+
+const (
+	defaultScanCount = 100
+	defaultBatchSize = 100
+)
+
+// KeysOptionsFunc represents a configuration function for key operations.
+type KeysOptionsFunc func(*keysOptions)
+
+type keysOptions struct {
+	scanCount int64
+	batchSize int
+	rate      int
+	typ       string
+	clock     clock.Clock
+}
+
+// WithScanCount configures the COUNT hint passed to SCAN.
+func WithScanCount(count int64) KeysOptionsFunc {
+	return func(o *keysOptions) {
+		o.scanCount = count
+	}
+}
+
+// WithBatchSize configures the number of keys unlinked in a single batch.
+func WithBatchSize(size int) KeysOptionsFunc {
+	return func(o *keysOptions) {
+		o.batchSize = size
+	}
+}
+
+// WithRateLimit configures the maximum number of keys processed per second
+// across all nodes. A zero rate disables limiting.
+func WithRateLimit(keysPerSecond int) KeysOptionsFunc {
+	return func(o *keysOptions) {
+		o.rate = keysPerSecond
+	}
+}
+
+// WithType restricts the scan to keys of the given type, e.g. "string"
+// or "hash". It requires Redis 6 or later.
+func WithType(typ string) KeysOptionsFunc {
+	return func(o *keysOptions) {
+		o.typ = typ
+	}
+}
+
+// WithKeysClock configures the clock the rate limit is measured with.
+func WithKeysClock(c clock.Clock) KeysOptionsFunc {
+	return func(o *keysOptions) {
+		o.clock = c
+	}
+}
+
+func newKeysOptions(opts []KeysOptionsFunc) *keysOptions {
+	o := &keysOptions{
+		scanCount: defaultScanCount,
+		batchSize: defaultBatchSize,
+		clock:     clock.Real,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.batchSize <= 0 {
+		o.batchSize = defaultBatchSize
+	}
+
+	return o
+}
+
+// ForEachKey calls fn for every key matching the pattern. On a cluster all
+// masters are scanned in parallel, so fn must be safe for concurrent use.
+//
+// The first error returned by fn, or the context being done, stops the scan.
+func ForEachKey(ctx context.Context, c Client, match string, fn func(key string) error, opts ...KeysOptionsFunc) error {
+	o := newKeysOptions(opts)
+	l := newLimiter(o.rate, o.clock)
+
+	return forEachBatch(ctx, c, match, o, func(_ *redis.Client, keys []string) error {
+		for _, key := range keys {
+			if err := l.wait(ctx, 1); err != nil {
+				return err
+			}
+
+			if err := fn(key); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// CountByPattern returns the number of keys matching the pattern.
+func CountByPattern(ctx context.Context, c Client, match string, opts ...KeysOptionsFunc) (int64, error) {
+	o := newKeysOptions(opts)
+	l := newLimiter(o.rate, o.clock)
+
+	var n int64
+	err := forEachBatch(ctx, c, match, o, func(_ *redis.Client, keys []string) error {
+		if err := l.wait(ctx, len(keys)); err != nil {
+			return err
+		}
+
+		atomic.AddInt64(&n, int64(len(keys)))
+
+		return nil
+	})
+
+	return atomic.LoadInt64(&n), err
+}
+
+// DeleteByPattern unlinks all keys matching the pattern and returns
+// the number of keys removed. Keys are unlinked in pipelined batches
+// on the node that owns them.
+func DeleteByPattern(ctx context.Context, c Client, match string, opts ...KeysOptionsFunc) (int64, error) {
+	o := newKeysOptions(opts)
+	l := newLimiter(o.rate, o.clock)
+
+	var n int64
+	err := forEachBatch(ctx, c, match, o, func(node *redis.Client, keys []string) error {
+		if err := l.wait(ctx, len(keys)); err != nil {
+			return err
+		}
+
+		cmds, err := node.Pipelined(func(p redis.Pipeliner) error {
+			for _, key := range keys {
+				p.Unlink(key)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, cmd := range cmds {
+			atomic.AddInt64(&n, cmd.(*redis.IntCmd).Val())
+		}
+
+		return nil
+	})
+
+	return atomic.LoadInt64(&n), err
+}
+
+// ScanParallel scans all masters concurrently and sends the matching keys to
+// the returned channel. The key channel is closed when the scan completes,
+// fails or the context is done; the error channel then yields the first
+// error, if any, and is closed.
+func ScanParallel(ctx context.Context, c Client, match string, opts ...KeysOptionsFunc) (<-chan string, <-chan error) {
+	o := newKeysOptions(opts)
+
+	keysCh := make(chan string)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(errCh)
+		defer close(keysCh)
+
+		err := forEachBatch(ctx, c, match, o, func(_ *redis.Client, keys []string) error {
+			for _, key := range keys {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case keysCh <- key:
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			errCh <- err
+		}
+	}()
+
+	return keysCh, errCh
+}
+
+// forEachBatch scans every master in parallel and calls fn with batches of
+// keys together with the node they were found on.
+func forEachBatch(ctx context.Context, c Client, match string, o *keysOptions, fn func(node *redis.Client, keys []string) error) error {
+	nodes, err := collectNodes(c.ForEachNode)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		ferr error
+	)
+
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(node *redis.Client) {
+			defer wg.Done()
+
+			if err := scanBatches(ctx, node, match, o, fn); err != nil {
+				once.Do(func() {
+					ferr = err
+					cancel()
+				})
+			}
+		}(node)
+	}
+	wg.Wait()
+
+	return ferr
+}
+
+func scanBatches(ctx context.Context, node *redis.Client, match string, o *keysOptions, fn func(node *redis.Client, keys []string) error) error {
+	var cursor uint64
+	batch := make([]string, 0, o.batchSize)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		keys, next, err := scanPage(node, cursor, match, o.scanCount, o.typ)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			batch = append(batch, key)
+			if len(batch) < o.batchSize {
+				continue
+			}
+
+			if err := fn(node, batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	return fn(node, batch)
+}
+
+// scanPage fetches a single page of keys starting at the cursor.
+func scanPage(node *redis.Client, cursor uint64, match string, count int64, typ string) ([]string, uint64, error) {
+	if typ == "" {
+		return node.Scan(cursor, match, count).Result()
+	}
+
+	args := []interface{}{"scan", cursor}
+	if match != "" {
+		args = append(args, "match", match)
+	}
+	if count > 0 {
+		args = append(args, "count", count)
+	}
+	args = append(args, "type", typ)
+
+	cmd := redis.NewScanCmd(node.Process, args...)
+	_ = node.Process(cmd)
+
+	return cmd.Result()
+}
+
+// collectNodes returns the clients of every node visited by forEach.
+func collectNodes(forEach func(fn func(client *redis.Client) error) error) ([]*redis.Client, error) {
+	var mu sync.Mutex
+	nodes := make([]*redis.Client, 0)
+	err := forEach(func(client *redis.Client) error {
+		mu.Lock()
+		nodes = append(nodes, client)
+		mu.Unlock()
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+// limiter spaces out work so that no more than rate units
+// are processed per second.
+type limiter struct {
+	clock clock.Clock
+
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newLimiter(rate int, c clock.Clock) *limiter {
+	if rate <= 0 {
+		return &limiter{clock: c}
+	}
+
+	return &limiter{clock: c, interval: time.Second / time.Duration(rate)}
+}
+
+func (l *limiter) wait(ctx context.Context, n int) error {
+	if l.interval == 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := l.clock.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	at := l.next
+	l.next = l.next.Add(time.Duration(n) * l.interval)
+	l.mu.Unlock()
+
+	d := at.Sub(now)
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	return clock.Sleep(ctx, l.clock, d)
+}
@@ -0,0 +1,210 @@
+package redisx_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/msales/pkg/v5/clock"
+	"github.com/msales/pkg/v5/redisx"
+)
+
+func TestForEachKey(t *testing.T) {
+	client1 := getClient()
+	client2 := getClient()
+
+	client1.Set("test1", 1, 0)
+	client2.Set("test2", 2, 0)
+	client2.Set("test3", 3, 0)
+	client2.Set("other", 4, 0)
+
+	client := newNodesClientMock(client1, client2)
+
+	var mu sync.Mutex
+	keys := []string{}
+	err := redisx.ForEachKey(context.Background(), client, "test*", func(key string) error {
+		mu.Lock()
+		keys = append(keys, key)
+		mu.Unlock()
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	sort.Strings(keys)
+	assert.Equal(t, []string{"test1", "test2", "test3"}, keys)
+}
+
+func TestForEachKey_FnError(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	client.Set("test1", 1, 0)
+
+	err := redisx.ForEachKey(context.Background(), client, "test*", func(key string) error {
+		return errors.New("test error")
+	})
+
+	assert.Error(t, err)
+}
+
+func TestForEachKey_CancelledContext(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	client.Set("test1", 1, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var called bool
+	err := redisx.ForEachKey(ctx, client, "test*", func(key string) error {
+		called = true
+		return nil
+	})
+
+	assert.Equal(t, context.Canceled, err)
+	assert.False(t, called)
+}
+
+func TestForEachKey_WithError(t *testing.T) {
+	err := redisx.ForEachKey(context.Background(), &erroredNodesClientMock{getClient()}, "test*", func(key string) error {
+		return nil
+	})
+
+	assert.Error(t, err)
+}
+
+func TestCountByPattern(t *testing.T) {
+	client1 := getClient()
+	client2 := getClient()
+
+	client1.Set("test1", 1, 0)
+	client2.Set("test2", 2, 0)
+	client2.Set("test3", 3, 0)
+	client2.Set("other", 4, 0)
+
+	client := newNodesClientMock(client1, client2)
+
+	n, err := redisx.CountByPattern(context.Background(), client, "test*", redisx.WithBatchSize(2))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+}
+
+func TestDeleteByPattern(t *testing.T) {
+	client1 := getClient()
+	client2 := getClient()
+
+	client1.Set("test1", 1, 0)
+	client2.Set("test2", 2, 0)
+	client2.Set("test3", 3, 0)
+	client2.Set("other", 4, 0)
+
+	client := newNodesClientMock(client1, client2)
+
+	n, err := redisx.DeleteByPattern(context.Background(), client, "test*", redisx.WithBatchSize(1), redisx.WithScanCount(10))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, int64(0), client1.Exists("test1").Val())
+	assert.Equal(t, int64(0), client2.Exists("test2", "test3").Val())
+	assert.Equal(t, int64(1), client2.Exists("other").Val())
+}
+
+func TestDeleteByPattern_RateLimit(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	client.Set("test1", 1, 0)
+	client.Set("test2", 2, 0)
+	client.Set("test3", 3, 0)
+
+	start := time.Now()
+	n, err := redisx.DeleteByPattern(context.Background(), client, "test*", redisx.WithBatchSize(1), redisx.WithRateLimit(100))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+}
+
+func TestDeleteByPattern_RateLimitCancelled(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	client.Set("test1", 1, 0)
+	client.Set("test2", 2, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := redisx.DeleteByPattern(ctx, client, "test*", redisx.WithBatchSize(1), redisx.WithRateLimit(1))
+
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestDeleteByPattern_RateLimitClock(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	client.Set("test1", 1, 0)
+	client.Set("test2", 2, 0)
+	client.Set("test3", 3, 0)
+
+	clk := clock.NewFake(time.Now())
+
+	type result struct {
+		n   int64
+		err error
+	}
+	done := make(chan result)
+	go func() {
+		n, err := redisx.DeleteByPattern(context.Background(), client, "test*",
+			redisx.WithBatchSize(1),
+			redisx.WithRateLimit(1),
+			redisx.WithKeysClock(clk),
+		)
+		done <- result{n: n, err: err}
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+
+	res := <-done
+	assert.NoError(t, res.err)
+	assert.Equal(t, int64(3), res.n)
+}
+
+func TestScanParallel(t *testing.T) {
+	client1 := getClient()
+	client2 := getClient()
+
+	client1.Set("test1", 1, 0)
+	client2.Set("test2", 2, 0)
+	client2.Set("test3", 3, 0)
+
+	client := newNodesClientMock(client1, client2)
+
+	keysCh, errCh := redisx.ScanParallel(context.Background(), client, "test*")
+
+	keys := []string{}
+	for key := range keysCh {
+		keys = append(keys, key)
+	}
+
+	assert.NoError(t, <-errCh)
+	sort.Strings(keys)
+	assert.Equal(t, []string{"test1", "test2", "test3"}, keys)
+}
+
+func TestScanParallel_CancelledContext(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	client.Set("test1", 1, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	keysCh, errCh := redisx.ScanParallel(ctx, client, "test*")
+
+	for range keysCh {
+	}
+
+	assert.Equal(t, context.Canceled, <-errCh)
+}
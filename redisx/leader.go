@@ -0,0 +1,384 @@
+package redisx
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/msales/pkg/v5/clock"
+)
+
+const (
+	acquireScript = `
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return redis.call('INCR', KEYS[2])
+end
+return 0
+`
+
+	renewScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`
+
+	releaseScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`
+)
+
+// Elector takes part in a leader election.
+type Elector interface {
+	// Run campaigns for leadership and keeps it renewed until the context
+	// is done, after which the leadership is released.
+	Run(ctx context.Context) error
+
+	// IsLeader reports whether the elector currently holds the leadership.
+	IsLeader() bool
+
+	// Token returns the fencing token of the current leadership, or zero
+	// when the elector is not the leader. Tokens increase with every
+	// election, so stale leaders can be rejected by the resources they use.
+	Token() int64
+}
+
+// ElectorOptionsFunc represents a configuration function for an Elector.
+type ElectorOptionsFunc func(*elector)
+
+// WithElectorID configures the unique ID of the candidate.
+func WithElectorID(id string) ElectorOptionsFunc {
+	return func(e *elector) {
+		e.id = id
+	}
+}
+
+// WithElectorTTL configures how long the leadership is held without renewal.
+func WithElectorTTL(ttl time.Duration) ElectorOptionsFunc {
+	return func(e *elector) {
+		e.ttl = ttl
+	}
+}
+
+// WithElectorInterval configures how often the leadership is renewed or,
+// for a follower, campaigned for. It should be well below the TTL.
+func WithElectorInterval(interval time.Duration) ElectorOptionsFunc {
+	return func(e *elector) {
+		e.interval = interval
+	}
+}
+
+// WithOnElected configures a function called in its own goroutine when the
+// leadership is gained. The context is cancelled when it is lost.
+func WithOnElected(fn func(ctx context.Context, token int64)) ElectorOptionsFunc {
+	return func(e *elector) {
+		e.onElected = fn
+	}
+}
+
+// WithOnRevoked configures a function called when the leadership is lost
+// or released.
+func WithOnRevoked(fn func()) ElectorOptionsFunc {
+	return func(e *elector) {
+		e.onRevoked = fn
+	}
+}
+
+// WithElectorClock configures the clock the renewals are timed with.
+func WithElectorClock(c clock.Clock) ElectorOptionsFunc {
+	return func(e *elector) {
+		e.clock = c
+	}
+}
+
+// NewElector returns an Elector for the named election held in redis.
+//
+// The leadership is a key set with SET NX PX and renewed while held;
+// every election increments a counter that serves as fencing token.
+func NewElector(c Client, name string, opts ...ElectorOptionsFunc) Elector {
+	prefix := "{" + name + "}:"
+
+	scripts := NewScriptRegistry(c)
+	s := &redisLeaderStore{
+		acquire: scripts.Register("acquire", acquireScript),
+		renew:   scripts.Register("renew", renewScript),
+		release: scripts.Register("release", releaseScript),
+		keys:    []string{prefix + "leader", prefix + "token"},
+	}
+
+	return newElector(s, opts)
+}
+
+// MemoryElectionOptionsFunc represents a configuration function for
+// a MemoryElection.
+type MemoryElectionOptionsFunc func(*MemoryElection)
+
+// WithMemoryElectionClock configures the clock the leadership expires with.
+func WithMemoryElectionClock(c clock.Clock) MemoryElectionOptionsFunc {
+	return func(m *MemoryElection) {
+		m.clock = c
+	}
+}
+
+// MemoryElection is an in-memory election that Electors created with
+// NewMemoryElector campaign in. It is meant for tests.
+type MemoryElection struct {
+	clock clock.Clock
+
+	mu      sync.Mutex
+	leader  string
+	token   int64
+	expires time.Time
+}
+
+// NewMemoryElection returns a new in-memory election.
+func NewMemoryElection(opts ...MemoryElectionOptionsFunc) *MemoryElection {
+	m := &MemoryElection{clock: clock.Real}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Leader returns the ID of the current leader, if any.
+func (m *MemoryElection) Leader() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.clock.Now().Before(m.expires) {
+		return ""
+	}
+
+	return m.leader
+}
+
+// Expire ends the current leadership, as if it was not renewed in time.
+func (m *MemoryElection) Expire() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.leader = ""
+}
+
+// NewMemoryElector returns an Elector campaigning in the in-memory election.
+func NewMemoryElector(m *MemoryElection, opts ...ElectorOptionsFunc) Elector {
+	return newElector(&memoryLeaderStore{m: m}, opts)
+}
+
+// leaderStore holds the leadership of a single election.
+type leaderStore interface {
+	acquireLeader(id string, ttl time.Duration) (token int64, err error)
+	renewLeader(id string, ttl time.Duration) (bool, error)
+	releaseLeader(id string) error
+}
+
+type elector struct {
+	store leaderStore
+	clock clock.Clock
+
+	id        string
+	ttl       time.Duration
+	interval  time.Duration
+	onElected func(ctx context.Context, token int64)
+	onRevoked func()
+
+	mu      sync.RWMutex
+	token   int64
+	renewed time.Time
+	cancel  context.CancelFunc
+}
+
+func newElector(s leaderStore, opts []ElectorOptionsFunc) *elector {
+	e := &elector{
+		store:     s,
+		clock:     clock.Real,
+		ttl:       15 * time.Second,
+		interval:  5 * time.Second,
+		onElected: func(context.Context, int64) {},
+		onRevoked: func() {},
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if e.id == "" {
+		e.id, _ = randomID()
+	}
+
+	return e
+}
+
+// Run campaigns for leadership until the context is done.
+func (e *elector) Run(ctx context.Context) error {
+	t := e.clock.NewTicker(e.interval)
+	defer t.Stop()
+
+	for {
+		e.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			if !e.IsLeader() {
+				return nil
+			}
+
+			e.revoke()
+			return e.store.releaseLeader(e.id)
+
+		case <-t.C():
+		}
+	}
+}
+
+func (e *elector) tick(ctx context.Context) {
+	// The key expires a TTL after the store received the command, which
+	// is no earlier than the time it was sent.
+	start := e.clock.Now()
+
+	if !e.IsLeader() {
+		token, err := e.store.acquireLeader(e.id, e.ttl)
+		if err != nil || token == 0 {
+			return
+		}
+
+		e.elect(ctx, token, start)
+		return
+	}
+
+	ok, err := e.store.renewLeader(e.id, e.ttl)
+	switch {
+	case err == nil && ok:
+		e.mu.Lock()
+		e.renewed = start
+		e.mu.Unlock()
+
+	case err == nil && !ok:
+		e.revoke()
+
+	default:
+		// The store is unreachable; the leadership lapses with the TTL.
+		// Revoke it now if it could lapse before the next renewal.
+		e.mu.RLock()
+		expired := e.clock.Since(e.renewed) >= e.ttl-e.interval
+		e.mu.RUnlock()
+
+		if expired {
+			e.revoke()
+		}
+	}
+}
+
+func (e *elector) elect(ctx context.Context, token int64, renewed time.Time) {
+	lctx, cancel := context.WithCancel(ctx)
+
+	e.mu.Lock()
+	e.token = token
+	e.renewed = renewed
+	e.cancel = cancel
+	e.mu.Unlock()
+
+	go e.onElected(lctx, token)
+}
+
+func (e *elector) revoke() {
+	e.mu.Lock()
+	e.token = 0
+	e.cancel()
+	e.mu.Unlock()
+
+	e.onRevoked()
+}
+
+// IsLeader reports whether the elector currently holds the leadership.
+func (e *elector) IsLeader() bool {
+	return e.Token() != 0
+}
+
+// Token returns the fencing token of the current leadership.
+func (e *elector) Token() int64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.token
+}
+
+type redisLeaderStore struct {
+	acquire *Script
+	renew   *Script
+	release *Script
+	keys    []string
+}
+
+func (s *redisLeaderStore) acquireLeader(id string, ttl time.Duration) (int64, error) {
+	res, err := s.acquire.Run(s.keys, id, ttl.Milliseconds())
+	if err != nil {
+		return 0, err
+	}
+
+	token, _ := res.(int64)
+	return token, nil
+}
+
+func (s *redisLeaderStore) renewLeader(id string, ttl time.Duration) (bool, error) {
+	res, err := s.renew.Run(s.keys[:1], id, ttl.Milliseconds())
+	if err != nil {
+		return false, err
+	}
+
+	return res == int64(1), nil
+}
+
+func (s *redisLeaderStore) releaseLeader(id string) error {
+	_, err := s.release.Run(s.keys[:1], id)
+
+	return err
+}
+
+type memoryLeaderStore struct {
+	m *MemoryElection
+}
+
+func (s *memoryLeaderStore) acquireLeader(id string, ttl time.Duration) (int64, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if s.m.leader != "" && s.m.clock.Now().Before(s.m.expires) {
+		return 0, nil
+	}
+
+	s.m.leader = id
+	s.m.expires = s.m.clock.Now().Add(ttl)
+	s.m.token++
+
+	return s.m.token, nil
+}
+
+func (s *memoryLeaderStore) renewLeader(id string, ttl time.Duration) (bool, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if s.m.leader != id || !s.m.clock.Now().Before(s.m.expires) {
+		return false, nil
+	}
+
+	s.m.expires = s.m.clock.Now().Add(ttl)
+
+	return true, nil
+}
+
+func (s *memoryLeaderStore) releaseLeader(id string) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if s.m.leader == id {
+		s.m.leader = ""
+	}
+
+	return nil
+}
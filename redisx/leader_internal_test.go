@@ -0,0 +1,86 @@
+package redisx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/msales/pkg/v5/clock"
+)
+
+type testLeaderStore struct {
+	renew func() (bool, error)
+}
+
+func (s *testLeaderStore) acquireLeader(string, time.Duration) (int64, error) {
+	return 1, nil
+}
+
+func (s *testLeaderStore) renewLeader(string, time.Duration) (bool, error) {
+	return s.renew()
+}
+
+func (s *testLeaderStore) releaseLeader(string) error {
+	return nil
+}
+
+func TestElector_RenewError(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	s := &testLeaderStore{
+		renew: func() (bool, error) {
+			return false, errors.New("test error")
+		},
+	}
+	e := newElector(s, []ElectorOptionsFunc{
+		WithElectorTTL(time.Minute),
+		WithElectorInterval(20 * time.Second),
+		WithElectorClock(clk),
+	})
+	ctx := context.Background()
+
+	e.tick(ctx)
+	assert.True(t, e.IsLeader())
+
+	clk.Advance(20 * time.Second)
+	e.tick(ctx)
+	assert.True(t, e.IsLeader())
+
+	// The key may expire before the next renewal.
+	clk.Advance(20 * time.Second)
+	e.tick(ctx)
+	assert.False(t, e.IsLeader())
+}
+
+func TestElector_RenewErrorAfterSlowRenewal(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	s := &testLeaderStore{
+		renew: func() (bool, error) {
+			clk.Advance(10 * time.Second)
+			return true, nil
+		},
+	}
+	e := newElector(s, []ElectorOptionsFunc{
+		WithElectorTTL(time.Minute),
+		WithElectorInterval(20 * time.Second),
+		WithElectorClock(clk),
+	})
+	ctx := context.Background()
+
+	e.tick(ctx)
+
+	clk.Advance(10 * time.Second)
+	e.tick(ctx)
+	assert.True(t, e.IsLeader())
+
+	s.renew = func() (bool, error) {
+		return false, errors.New("test error")
+	}
+
+	// The renewal was sent 40s ago, though it completed only 30s ago.
+	clk.Advance(30 * time.Second)
+	e.tick(ctx)
+	assert.False(t, e.IsLeader())
+}
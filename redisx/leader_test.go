@@ -0,0 +1,148 @@
+package redisx_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/msales/pkg/v5/clock"
+	"github.com/msales/pkg/v5/redisx"
+)
+
+func TestElector(t *testing.T) {
+	client := redisx.NewClient(getClient())
+
+	elected := make(chan int64, 2)
+	newElector := func(id string) redisx.Elector {
+		return redisx.NewElector(client, "election",
+			redisx.WithElectorID(id),
+			redisx.WithElectorTTL(time.Second),
+			redisx.WithElectorInterval(5*time.Millisecond),
+			redisx.WithOnElected(func(ctx context.Context, token int64) {
+				elected <- token
+			}),
+		)
+	}
+	e1 := newElector("first")
+	e2 := newElector("second")
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, e1.Run(ctx1))
+	}()
+
+	assert.Equal(t, int64(1), <-elected)
+	assert.True(t, e1.IsLeader())
+	assert.Equal(t, int64(1), e1.Token())
+
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, e2.Run(ctx2))
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	assert.False(t, e2.IsLeader())
+	assert.Equal(t, int64(0), e2.Token())
+
+	cancel1()
+
+	assert.Equal(t, int64(2), <-elected)
+	assert.True(t, e2.IsLeader())
+	assert.False(t, e1.IsLeader())
+
+	cancel2()
+	wg.Wait()
+	assert.Equal(t, int64(0), client.Exists("{election}:leader").Val())
+}
+
+func TestMemoryElector(t *testing.T) {
+	election := redisx.NewMemoryElection()
+
+	elected := make(chan context.Context, 2)
+	revoked := make(chan struct{}, 1)
+	e := redisx.NewMemoryElector(election,
+		redisx.WithElectorID("first"),
+		redisx.WithElectorTTL(time.Second),
+		redisx.WithElectorInterval(time.Millisecond),
+		redisx.WithOnElected(func(ctx context.Context, token int64) {
+			elected <- ctx
+		}),
+		redisx.WithOnRevoked(func() {
+			revoked <- struct{}{}
+		}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, e.Run(ctx))
+	}()
+
+	leaderCtx := <-elected
+	assert.Equal(t, "first", election.Leader())
+
+	election.Expire()
+
+	<-revoked
+	<-leaderCtx.Done()
+
+	<-elected
+	assert.Equal(t, int64(2), e.Token())
+
+	cancel()
+	<-done
+	<-revoked
+	assert.Equal(t, "", election.Leader())
+}
+
+func TestMemoryElector_Clock(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	election := redisx.NewMemoryElection(redisx.WithMemoryElectionClock(clk))
+
+	elected := make(chan struct{}, 1)
+	revoked := make(chan struct{}, 1)
+	e := redisx.NewMemoryElector(election,
+		redisx.WithElectorID("first"),
+		redisx.WithElectorTTL(time.Minute),
+		redisx.WithElectorInterval(time.Hour),
+		redisx.WithElectorClock(clk),
+		redisx.WithOnElected(func(ctx context.Context, token int64) {
+			elected <- struct{}{}
+		}),
+		redisx.WithOnRevoked(func() {
+			revoked <- struct{}{}
+		}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, e.Run(ctx))
+	}()
+
+	<-elected
+	assert.Equal(t, "first", election.Leader())
+
+	clk.Advance(time.Minute)
+	assert.Equal(t, "", election.Leader())
+
+	// The renewal on the next tick finds the leadership expired.
+	clk.BlockUntil(1)
+	clk.Advance(time.Hour)
+	<-revoked
+	assert.False(t, e.IsLeader())
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,253 @@
+package redisx
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis"
+)
+
+const maxRedirects = 8
+
+// ErrRingClient means that a pipeline routed by hash slot was used with
+// a ring client, whose keys are not distributed by hash slot.
+var ErrRingClient = errors.New("redisx: ring clients are not supported")
+
+// ClusterPipeline queues commands and executes them in one pipeline per node,
+// grouping them by the hash slot of their key.
+//
+// Unlike a regular pipeline, commands for different slots never share a
+// request, so they cannot fail with CROSSSLOT. MOVED and ASK redirections
+// are followed for every command.
+//
+// Single node and cluster clients are supported. A ring shards its keys by
+// its own hashing rather than by slot, so Exec fails with ErrRingClient
+// for a ring client.
+type ClusterPipeline struct {
+	client Client
+
+	cmds []*slotCmd
+}
+
+type slotCmd struct {
+	key  string
+	cmd  *redis.Cmd
+	ask  bool
+	addr string
+}
+
+// NewClusterPipeline returns a pipeline using the given client.
+func NewClusterPipeline(c Client) *ClusterPipeline {
+	return &ClusterPipeline{client: c}
+}
+
+// Do queues a command routed by the given key. The returned command holds
+// the result once Exec has been called.
+func (p *ClusterPipeline) Do(key string, args ...interface{}) *redis.Cmd {
+	cmd := redis.NewCmd(args...)
+	p.cmds = append(p.cmds, &slotCmd{key: key, cmd: cmd})
+
+	return cmd
+}
+
+// Len returns the number of queued commands.
+func (p *ClusterPipeline) Len() int {
+	return len(p.cmds)
+}
+
+// Exec executes all queued commands, concurrently per node, and returns them
+// in the order they were queued. The error is the first command error, if any.
+func (p *ClusterPipeline) Exec() ([]*redis.Cmd, error) {
+	queued := p.cmds
+	p.cmds = nil
+
+	cmds := make([]*redis.Cmd, 0, len(queued))
+	for _, sc := range queued {
+		cmds = append(cmds, sc.cmd)
+	}
+
+	if len(queued) == 0 {
+		return cmds, nil
+	}
+
+	if isRing(p.client) {
+		return cmds, ErrRingClient
+	}
+
+	nodes, err := p.nodes()
+	if err != nil {
+		return cmds, err
+	}
+
+	route, err := p.router(nodes)
+	if err != nil {
+		return cmds, err
+	}
+
+	for _, sc := range queued {
+		sc.addr = route(Slot(sc.key))
+	}
+
+	for i := 0; i <= maxRedirects && len(queued) > 0; i++ {
+		if err := p.exec(nodes, queued); err != nil {
+			return cmds, err
+		}
+
+		queued = p.redirects(queued)
+
+		for _, sc := range queued {
+			if _, ok := nodes[sc.addr]; ok {
+				continue
+			}
+
+			// The redirection points to a node we do not know yet.
+			if nodes, err = p.nodes(); err != nil {
+				return cmds, err
+			}
+			break
+		}
+	}
+
+	for _, cmd := range cmds {
+		if err := cmd.Err(); err != nil {
+			return cmds, err
+		}
+	}
+
+	return cmds, nil
+}
+
+// exec runs the commands in one pipeline per node.
+func (p *ClusterPipeline) exec(nodes map[string]*redis.Client, queued []*slotCmd) error {
+	groups := map[string][]*slotCmd{}
+	for _, sc := range queued {
+		groups[sc.addr] = append(groups[sc.addr], sc)
+	}
+
+	var wg sync.WaitGroup
+	for addr, group := range groups {
+		node, ok := nodes[addr]
+		if !ok {
+			return fmt.Errorf("redisx: unknown node %s", addr)
+		}
+
+		wg.Add(1)
+		go func(node *redis.Client, group []*slotCmd) {
+			defer wg.Done()
+
+			_, _ = node.Pipelined(func(pipe redis.Pipeliner) error {
+				for _, sc := range group {
+					if sc.ask {
+						_ = pipe.Process(redis.NewCmd("asking"))
+					}
+					_ = pipe.Process(sc.cmd)
+				}
+
+				return nil
+			})
+		}(node, group)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// redirects returns the commands that were redirected, updated with the
+// node they have to be sent to.
+func (p *ClusterPipeline) redirects(queued []*slotCmd) []*slotCmd {
+	var next []*slotCmd
+	for _, sc := range queued {
+		moved, ask, addr := isRedirect(sc.cmd.Err())
+		if !moved && !ask {
+			continue
+		}
+
+		sc.addr, sc.ask = addr, ask
+		next = append(next, sc)
+	}
+
+	return next
+}
+
+// nodes returns the node clients keyed by address.
+func (p *ClusterPipeline) nodes() (map[string]*redis.Client, error) {
+	list, err := collectNodes(p.client.ForEachNode)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*redis.Client, len(list))
+	for _, node := range list {
+		nodes[node.Options().Addr] = node
+	}
+
+	return nodes, nil
+}
+
+// isRing reports whether the client is a ring client.
+func isRing(c Client) bool {
+	cc, ok := c.(*client)
+	if !ok {
+		return false
+	}
+
+	_, ok = cc.UniversalClient.(*redis.Ring)
+	return ok
+}
+
+// router returns a function that maps a slot to the address of its node.
+func (p *ClusterPipeline) router(nodes map[string]*redis.Client) (func(slot int) string, error) {
+	if len(nodes) == 1 {
+		for addr := range nodes {
+			return func(int) string { return addr }, nil
+		}
+	}
+
+	slots, err := p.client.ClusterSlots().Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var owners [SlotCount]string
+	for _, s := range slots {
+		if len(s.Nodes) == 0 {
+			continue
+		}
+
+		for i := s.Start; i <= s.End && i < SlotCount; i++ {
+			owners[i] = s.Nodes[0].Addr
+		}
+	}
+
+	return func(slot int) string {
+		return owners[slot]
+	}, nil
+}
+
+// isRedirect parses MOVED and ASK errors, returning the target address.
+func isRedirect(err error) (moved bool, ask bool, addr string) {
+	if err == nil {
+		return false, false, ""
+	}
+
+	parts := strings.Fields(err.Error())
+	if len(parts) != 3 {
+		return false, false, ""
+	}
+
+	if _, err := strconv.Atoi(parts[1]); err != nil {
+		return false, false, ""
+	}
+
+	switch parts[0] {
+	case "MOVED":
+		return true, false, parts[2]
+	case "ASK":
+		return false, true, parts[2]
+	default:
+		return false, false, ""
+	}
+}
@@ -0,0 +1,160 @@
+package redisx_test
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/msales/pkg/v5/redisx"
+)
+
+func TestClusterPipeline_Exec(t *testing.T) {
+	client1 := getClient()
+	client2 := getClient()
+
+	c := newSlotsClientMock(client1, client2)
+	p := redisx.NewClusterPipeline(c)
+
+	// "foo" hashes to slot 12182 and "bar" to slot 5061.
+	p.Do("foo", "set", "foo", "1")
+	p.Do("bar", "set", "bar", "2")
+	foo := p.Do("foo", "get", "foo")
+	bar := p.Do("bar", "get", "bar")
+	assert.Equal(t, 4, p.Len())
+
+	cmds, err := p.Exec()
+
+	assert.NoError(t, err)
+	assert.Len(t, cmds, 4)
+	assert.Equal(t, foo, cmds[2])
+	assert.Equal(t, "1", foo.Val())
+	assert.Equal(t, "2", bar.Val())
+	assert.Equal(t, "1", client2.Get("foo").Val())
+	assert.Equal(t, "2", client1.Get("bar").Val())
+	assert.Equal(t, 0, p.Len())
+}
+
+func TestClusterPipeline_ExecSingleNode(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	p := redisx.NewClusterPipeline(client)
+
+	p.Do("foo", "set", "foo", "1")
+	p.Do("bar", "set", "bar", "2")
+	mget := p.Do("foo", "mget", "foo", "bar")
+
+	_, err := p.Exec()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"1", "2"}, mget.Val())
+}
+
+func TestClusterPipeline_ExecRedirects(t *testing.T) {
+	client1 := getClient()
+	client2 := getClient()
+	client2.Set("owner", "1", 0)
+
+	tests := []struct {
+		name     string
+		redirect string
+	}{
+		{
+			name:     "moved",
+			redirect: "MOVED",
+		},
+		{
+			name:     "ask",
+			redirect: "ASK",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := redisx.NewClusterPipeline(newSlotsClientMock(client1, client2))
+
+			// "bar" is owned by the first node, which redirects to the second.
+			script := fmt.Sprintf(
+				"if redis.call('GET', 'owner') == '1' then return 'ok' end return redis.error_reply('%s 5061 %s')",
+				tt.redirect,
+				client2.Options().Addr,
+			)
+			cmd := p.Do("bar", "eval", script, 0)
+
+			_, err := p.Exec()
+
+			assert.NoError(t, err)
+			assert.Equal(t, "ok", cmd.Val())
+		})
+	}
+}
+
+func TestClusterPipeline_ExecError(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	client.Set("foo", "1", 0)
+	p := redisx.NewClusterPipeline(client)
+
+	p.Do("foo", "hget", "foo", "field")
+	ok := p.Do("bar", "set", "bar", "2")
+
+	_, err := p.Exec()
+
+	assert.Error(t, err)
+	assert.NoError(t, ok.Err())
+}
+
+func TestClusterPipeline_ExecEmpty(t *testing.T) {
+	p := redisx.NewClusterPipeline(redisx.NewClient(getClient()))
+
+	cmds, err := p.Exec()
+
+	assert.NoError(t, err)
+	assert.Empty(t, cmds)
+}
+
+func TestClusterPipeline_ExecRing(t *testing.T) {
+	ring := redis.NewRing(&redis.RingOptions{
+		Addrs: map[string]string{
+			"shard1": getClient().Options().Addr,
+			"shard2": getClient().Options().Addr,
+		},
+	})
+	defer ring.Close()
+
+	p := redisx.NewClusterPipeline(redisx.NewRingClient(ring))
+	cmd := p.Do("foo", "set", "foo", "1")
+
+	cmds, err := p.Exec()
+
+	assert.Equal(t, redisx.ErrRingClient, err)
+	assert.Equal(t, []*redis.Cmd{cmd}, cmds)
+	assert.Equal(t, 0, p.Len())
+}
+
+// slotsClientMock splits the slots evenly between two nodes.
+type slotsClientMock struct {
+	*nodesClientMock
+}
+
+func newSlotsClientMock(client1, client2 *redis.Client) *slotsClientMock {
+	return &slotsClientMock{newNodesClientMock(client1, client2)}
+}
+
+func (c *slotsClientMock) ClusterSlots() *redis.ClusterSlotsCmd {
+	node := func(client *redis.Client) string {
+		host, port, _ := net.SplitHostPort(client.Options().Addr)
+		return fmt.Sprintf("{'%s', %s}", host, port)
+	}
+
+	// Let the server reply with the slots, as the command value cannot be set.
+	script := fmt.Sprintf(
+		"return {{0, 8191, %s}, {8192, 16383, %s}}",
+		node(c.Masters[0]),
+		node(c.Masters[1]),
+	)
+	cmd := redis.NewClusterSlotsCmd("eval", script, 0)
+	_ = c.Process(cmd)
+
+	return cmd
+}
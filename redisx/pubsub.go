@@ -0,0 +1,255 @@
+package redisx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// ErrSubscriberClosed means that the Subscriber has been closed.
+var ErrSubscriberClosed = errors.New("redisx: subscriber closed")
+
+// MessageHandler handles a pub/sub message.
+type MessageHandler func(msg *redis.Message)
+
+// SubscriberOptionsFunc represents a configuration function for a Subscriber.
+type SubscriberOptionsFunc func(*Subscriber)
+
+// WithBufferSize configures the number of messages buffered per handler.
+// Messages arriving at a full buffer are dropped.
+func WithBufferSize(size int) SubscriberOptionsFunc {
+	return func(s *Subscriber) {
+		s.bufferSize = size
+	}
+}
+
+// WithHealthCheckInterval configures how often an idle connection is pinged
+// to detect connection loss.
+func WithHealthCheckInterval(interval time.Duration) SubscriberOptionsFunc {
+	return func(s *Subscriber) {
+		s.healthCheck = interval
+	}
+}
+
+// WithDropFunc configures a function called for every dropped message.
+func WithDropFunc(fn func(msg *redis.Message)) SubscriberOptionsFunc {
+	return func(s *Subscriber) {
+		s.dropFn = fn
+	}
+}
+
+// Subscriber fans out messages of redis channels and patterns to handlers.
+//
+// Each handler runs in its own goroutine with a bounded buffer, so a slow
+// handler drops its own messages instead of blocking the others. After a
+// connection loss the subscriber reconnects and resubscribes to all channels
+// and patterns.
+type Subscriber struct {
+	pubsub *redis.PubSub
+
+	bufferSize  int
+	healthCheck time.Duration
+	dropFn      func(msg *redis.Message)
+
+	mu       sync.RWMutex
+	channels map[string][]*subscription
+	patterns map[string][]*subscription
+	closed   bool
+
+	dropped int64
+	wg      sync.WaitGroup
+}
+
+// NewSubscriber returns a Subscriber using the given client.
+func NewSubscriber(c Client, opts ...SubscriberOptionsFunc) *Subscriber {
+	s := &Subscriber{
+		pubsub:      c.Subscribe(),
+		bufferSize:  100,
+		healthCheck: time.Minute,
+		dropFn:      func(*redis.Message) {},
+		channels:    map[string][]*subscription{},
+		patterns:    map[string][]*subscription{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Subscribe adds a handler for messages published to the channel.
+func (s *Subscriber) Subscribe(channel string, h MessageHandler) error {
+	return s.add(s.channels, channel, h, s.pubsub.Subscribe)
+}
+
+// PSubscribe adds a handler for messages published to channels matching
+// the pattern.
+func (s *Subscriber) PSubscribe(pattern string, h MessageHandler) error {
+	return s.add(s.patterns, pattern, h, s.pubsub.PSubscribe)
+}
+
+func (s *Subscriber) add(subs map[string][]*subscription, name string, h MessageHandler, subscribe func(...string) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrSubscriberClosed
+	}
+
+	if _, ok := subs[name]; !ok {
+		if err := subscribe(name); err != nil {
+			return err
+		}
+	}
+
+	sub := &subscription{
+		handler: h,
+		ch:      make(chan *redis.Message, s.bufferSize),
+	}
+	subs[name] = append(subs[name], sub)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		sub.run()
+	}()
+
+	return nil
+}
+
+// Dropped returns the number of messages dropped because a handler buffer was full.
+func (s *Subscriber) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+// Run receives messages and dispatches them to the handlers until the
+// context is done or the subscriber is closed.
+func (s *Subscriber) Run(ctx context.Context) error {
+	var errCount int
+	for ctx.Err() == nil {
+		msg, err := s.pubsub.ReceiveTimeout(s.healthCheck)
+		if err != nil {
+			if s.isClosed() {
+				return nil
+			}
+
+			if isTimeout(err) {
+				// Ping the idle connection so a lost one is replaced.
+				_ = s.pubsub.Ping()
+				continue
+			}
+
+			errCount++
+			if err := sleep(ctx, backoff(errCount)); err != nil {
+				return nil
+			}
+			continue
+		}
+		errCount = 0
+
+		if m, ok := msg.(*redis.Message); ok {
+			s.dispatch(m)
+		}
+	}
+
+	return nil
+}
+
+func (s *Subscriber) dispatch(msg *redis.Message) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return
+	}
+
+	subs := s.channels[msg.Channel]
+	if msg.Pattern != "" {
+		subs = s.patterns[msg.Pattern]
+	}
+
+	for _, sub := range subs {
+		select {
+		case sub.ch <- msg:
+		default:
+			atomic.AddInt64(&s.dropped, 1)
+			s.dropFn(msg)
+		}
+	}
+}
+
+// Close unsubscribes from everything and waits for the handlers to process
+// their buffered messages.
+func (s *Subscriber) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+
+	for _, subs := range []map[string][]*subscription{s.channels, s.patterns} {
+		for _, list := range subs {
+			for _, sub := range list {
+				close(sub.ch)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	err := s.pubsub.Close()
+	s.wg.Wait()
+
+	return err
+}
+
+func (s *Subscriber) isClosed() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.closed
+}
+
+type subscription struct {
+	handler MessageHandler
+	ch      chan *redis.Message
+}
+
+func (s *subscription) run() {
+	for msg := range s.ch {
+		s.handler(msg)
+	}
+}
+
+func isTimeout(err error) bool {
+	var nerr net.Error
+	return errors.As(err, &nerr) && nerr.Timeout()
+}
+
+func backoff(attempt int) time.Duration {
+	d := time.Duration(attempt) * 100 * time.Millisecond
+	if d > 5*time.Second {
+		d = 5 * time.Second
+	}
+
+	return d
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,171 @@
+package redisx_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/msales/pkg/v5/redisx"
+)
+
+func TestSubscriber(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	s := redisx.NewSubscriber(client, redisx.WithHealthCheckInterval(10*time.Millisecond))
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+
+	var mu sync.Mutex
+	got := map[string][]string{}
+	handler := func(name string) redisx.MessageHandler {
+		return func(msg *redis.Message) {
+			mu.Lock()
+			got[name] = append(got[name], msg.Payload)
+			mu.Unlock()
+			wg.Done()
+		}
+	}
+
+	assert.NoError(t, s.Subscribe("channel", handler("first")))
+	assert.NoError(t, s.Subscribe("channel", handler("second")))
+	assert.NoError(t, s.PSubscribe("chan*", handler("pattern")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	waitFor(t, time.Second, func() bool {
+		return client.Publish("channel", "message").Val() == 2
+	})
+
+	wg.Wait()
+	assert.NoError(t, s.Close())
+
+	assert.Equal(t, map[string][]string{
+		"first":   {"message"},
+		"second":  {"message"},
+		"pattern": {"message"},
+	}, got)
+	assert.Equal(t, int64(0), s.Dropped())
+}
+
+func TestSubscriber_Resubscribes(t *testing.T) {
+	srv, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	client := redisx.NewClient(redis.NewClient(&redis.Options{Addr: srv.Addr()}))
+	s := redisx.NewSubscriber(client, redisx.WithHealthCheckInterval(10*time.Millisecond))
+
+	var mu sync.Mutex
+	got := map[string]bool{}
+	handler := func(name string) redisx.MessageHandler {
+		return func(msg *redis.Message) {
+			mu.Lock()
+			got[name+":"+msg.Payload] = true
+			mu.Unlock()
+		}
+	}
+	received := func(payload string) func() bool {
+		return func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+
+			return got["channel:"+payload] && got["pattern:"+payload]
+		}
+	}
+
+	assert.NoError(t, s.Subscribe("channel", handler("channel")))
+	assert.NoError(t, s.PSubscribe("chan*", handler("pattern")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	waitFor(t, time.Second, func() bool {
+		return client.Publish("channel", "before").Val() == 2
+	})
+	waitFor(t, time.Second, received("before"))
+
+	// Drop all connections, as on a server restart.
+	srv.Close()
+	assert.NoError(t, srv.Restart())
+
+	waitFor(t, 5*time.Second, func() bool {
+		return client.Publish("channel", "after").Val() == 2
+	})
+	waitFor(t, time.Second, received("after"))
+
+	assert.NoError(t, s.Close())
+}
+
+func TestSubscriber_DropsMessages(t *testing.T) {
+	client := redisx.NewClient(getClient())
+
+	var dropped int
+	s := redisx.NewSubscriber(client,
+		redisx.WithBufferSize(1),
+		redisx.WithHealthCheckInterval(10*time.Millisecond),
+		redisx.WithDropFunc(func(msg *redis.Message) {
+			dropped++
+		}),
+	)
+
+	block := make(chan struct{})
+	received := make(chan struct{}, 3)
+	assert.NoError(t, s.Subscribe("channel", func(msg *redis.Message) {
+		received <- struct{}{}
+		<-block
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	waitFor(t, time.Second, func() bool {
+		return client.Publish("channel", "message").Val() == 1
+	})
+	<-received
+
+	client.Publish("channel", "message")
+	client.Publish("channel", "message")
+
+	waitFor(t, time.Second, func() bool {
+		return s.Dropped() == 1
+	})
+
+	close(block)
+	assert.NoError(t, s.Close())
+	assert.Equal(t, 1, dropped)
+}
+
+func TestSubscriber_Closed(t *testing.T) {
+	s := redisx.NewSubscriber(redisx.NewClient(getClient()))
+	assert.NoError(t, s.Close())
+
+	err := s.Subscribe("channel", func(msg *redis.Message) {})
+
+	assert.Equal(t, redisx.ErrSubscriberClosed, err)
+}
+
+// waitFor polls the condition until it holds, failing the test once the
+// timeout expires. Unlike assert.Eventually, it never runs the condition
+// concurrently.
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			assert.FailNow(t, "condition not satisfied in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
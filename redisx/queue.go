@@ -0,0 +1,375 @@
+package redisx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+
+	"github.com/msales/pkg/v5/retry"
+)
+
+const (
+	promoteScript = `
+local jobs = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, job in ipairs(jobs) do
+	redis.call('ZREM', KEYS[1], job)
+	redis.call('LPUSH', KEYS[2], job)
+end
+local expired = redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, job in ipairs(expired) do
+	redis.call('ZREM', KEYS[3], job)
+	redis.call('RPUSH', KEYS[2], job)
+end
+return #jobs + #expired
+`
+
+	dequeueScript = `
+local job = redis.call('RPOP', KEYS[1])
+if not job then
+	return false
+end
+redis.call('ZADD', KEYS[2], ARGV[1], job)
+local id = string.sub(job, 1, string.find(job, ':', 1, true) - 1)
+return {job, redis.call('HINCRBY', KEYS[3], id, 1)}
+`
+
+	// finishScript completes a processing job, unless its visibility timeout
+	// expired and it has been handed out again.
+	finishScript = `
+local score = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not score or tonumber(score) ~= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call('ZREM', KEYS[1], ARGV[1])
+if ARGV[3] == 'retry' then
+	redis.call('ZADD', KEYS[2], ARGV[5], ARGV[1])
+	return 1
+end
+redis.call('HDEL', KEYS[4], ARGV[4])
+if ARGV[3] == 'bury' then
+	redis.call('LPUSH', KEYS[3], ARGV[1])
+end
+return 1
+`
+)
+
+// Job represents a queued job.
+type Job struct {
+	// ID is the unique job ID.
+	ID string
+
+	// Payload is the job data given on enqueue.
+	Payload []byte
+
+	// Attempt is the number of the current attempt, starting at 1.
+	Attempt int
+
+	member   string
+	deadline int64
+}
+
+// JobHandler handles a job. A job whose handler returns an error is retried
+// according to the queue retry policy.
+type JobHandler func(ctx context.Context, job *Job) error
+
+// QueueStats represents the number of jobs in each state.
+type QueueStats struct {
+	Ready      int64
+	Delayed    int64
+	Processing int64
+	Dead       int64
+}
+
+// QueueOptionsFunc represents a configuration function for a Queue.
+type QueueOptionsFunc func(*Queue)
+
+// WithConcurrency configures the number of jobs handled concurrently by Run.
+func WithConcurrency(n int) QueueOptionsFunc {
+	return func(q *Queue) {
+		q.concurrency = n
+	}
+}
+
+// WithVisibilityTimeout configures how long a job may be processing before it
+// is considered lost and handed out again. It is also the handler deadline.
+func WithVisibilityTimeout(timeout time.Duration) QueueOptionsFunc {
+	return func(q *Queue) {
+		q.visibility = timeout
+	}
+}
+
+// WithPollInterval configures how long an idle worker waits before checking
+// for jobs again.
+func WithPollInterval(interval time.Duration) QueueOptionsFunc {
+	return func(q *Queue) {
+		q.poll = interval
+	}
+}
+
+// WithQueueRetry configures the retry policy of failed jobs. The sleep of
+// the policy is the delay before the next attempt; once the policy stops,
+// the job is moved to the dead-letter queue.
+func WithQueueRetry(policy retry.Policy) QueueOptionsFunc {
+	return func(q *Queue) {
+		q.policy = policy
+	}
+}
+
+// Queue is a redis backed job queue supporting delayed jobs, retries and
+// a dead-letter queue.
+//
+// Ready and dead jobs are kept in lists, delayed and processing jobs in
+// sorted sets scored by time. All keys share the queue name as hash tag,
+// so a queue lives in a single cluster slot.
+type Queue struct {
+	client  Client
+	scripts *ScriptRegistry
+
+	ready      string
+	delayed    string
+	processing string
+	dead       string
+	attempts   string
+
+	concurrency int
+	visibility  time.Duration
+	poll        time.Duration
+	policy      retry.Policy
+}
+
+// NewQueue returns the queue with the given name.
+func NewQueue(c Client, name string, opts ...QueueOptionsFunc) *Queue {
+	prefix := "{" + name + "}:"
+
+	q := &Queue{
+		client:      c,
+		scripts:     NewScriptRegistry(c),
+		ready:       prefix + "ready",
+		delayed:     prefix + "delayed",
+		processing:  prefix + "processing",
+		dead:        prefix + "dead",
+		attempts:    prefix + "attempts",
+		concurrency: 1,
+		visibility:  time.Minute,
+		poll:        time.Second,
+		policy:      retry.ExponentialPolicy(5, time.Second),
+	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	q.scripts.Register("promote", promoteScript)
+	q.scripts.Register("dequeue", dequeueScript)
+	q.scripts.Register("finish", finishScript)
+
+	return q
+}
+
+// Enqueue adds a job with the payload to the queue. The job becomes ready
+// after the delay. It returns the ID of the job.
+func (q *Queue) Enqueue(payload []byte, delay time.Duration) (string, error) {
+	id, err := randomID()
+	if err != nil {
+		return "", err
+	}
+
+	member := id + ":" + string(payload)
+	if delay <= 0 {
+		return id, q.client.LPush(q.ready, member).Err()
+	}
+
+	return id, q.client.ZAdd(q.delayed, redis.Z{
+		Score:  float64(toMillis(time.Now().Add(delay))),
+		Member: member,
+	}).Err()
+}
+
+// Stats returns the number of jobs in each state.
+func (q *Queue) Stats() (QueueStats, error) {
+	var ready, delayed, processing, dead *redis.IntCmd
+	_, err := q.client.Pipelined(func(p redis.Pipeliner) error {
+		ready = p.LLen(q.ready)
+		delayed = p.ZCard(q.delayed)
+		processing = p.ZCard(q.processing)
+		dead = p.LLen(q.dead)
+
+		return nil
+	})
+	if err != nil {
+		return QueueStats{}, err
+	}
+
+	return QueueStats{
+		Ready:      ready.Val(),
+		Delayed:    delayed.Val(),
+		Processing: processing.Val(),
+		Dead:       dead.Val(),
+	}, nil
+}
+
+// Run handles jobs with the configured concurrency until the context is done
+// or a redis error occurs. Jobs being handled are completed before Run returns.
+func (q *Queue) Run(ctx context.Context, h JobHandler) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		rerr error
+	)
+
+	for i := 0; i < q.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := q.work(ctx, h); err != nil {
+				once.Do(func() {
+					rerr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	return rerr
+}
+
+func (q *Queue) work(ctx context.Context, h JobHandler) error {
+	for ctx.Err() == nil {
+		if _, err := q.scripts.Run("promote", []string{q.delayed, q.ready, q.processing}, toMillis(time.Now()), 100); err != nil {
+			return err
+		}
+
+		job, err := q.dequeue()
+		if err != nil {
+			return err
+		}
+
+		if job == nil {
+			_ = sleep(ctx, q.poll)
+			continue
+		}
+
+		if err := q.handle(h, job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (q *Queue) dequeue() (*Job, error) {
+	deadline := toMillis(time.Now().Add(q.visibility))
+	res, err := q.scripts.Run("dequeue", []string{q.ready, q.processing, q.attempts}, deadline)
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	vals, ok := res.([]interface{})
+	if !ok || len(vals) != 2 {
+		return nil, fmt.Errorf("redisx: unexpected dequeue reply %v", res)
+	}
+
+	member, _ := vals[0].(string)
+	attempt, _ := vals[1].(int64)
+
+	i := strings.IndexByte(member, ':')
+	if i < 0 {
+		return nil, fmt.Errorf("redisx: invalid job %q", member)
+	}
+
+	return &Job{
+		ID:       member[:i],
+		Payload:  []byte(member[i+1:]),
+		Attempt:  int(attempt),
+		member:   member,
+		deadline: deadline,
+	}, nil
+}
+
+func (q *Queue) handle(h JobHandler, job *Job) error {
+	// A job handed out again after its visibility timeout may already
+	// have used up its attempts.
+	if job.Attempt > 1 {
+		if _, ok := q.backoff(job.Attempt - 1); !ok {
+			return q.bury(job)
+		}
+	}
+
+	// The handler gets its own context, so a job in progress is not
+	// aborted when Run is stopped.
+	ctx, cancel := context.WithTimeout(context.Background(), q.visibility)
+	err := h(ctx, job)
+	cancel()
+
+	if err == nil {
+		return q.ack(job)
+	}
+
+	delay, ok := q.backoff(job.Attempt)
+	if !ok {
+		return q.bury(job)
+	}
+
+	return q.finish(job, "retry", toMillis(time.Now().Add(delay)))
+}
+
+func (q *Queue) ack(job *Job) error {
+	return q.finish(job, "ack", 0)
+}
+
+// bury moves the job to the dead-letter queue.
+func (q *Queue) bury(job *Job) error {
+	return q.finish(job, "bury", 0)
+}
+
+func (q *Queue) finish(job *Job, action string, runAt int64) error {
+	keys := []string{q.processing, q.delayed, q.dead, q.attempts}
+	_, err := q.scripts.Run("finish", keys, job.member, job.deadline, action, job.ID, runAt)
+
+	return err
+}
+
+// backoff returns the delay after the given failed attempt, or false when
+// no attempts are left.
+func (q *Queue) backoff(attempt int) (time.Duration, bool) {
+	b := q.policy.Backoff()
+
+	var (
+		delay time.Duration
+		ok    bool
+	)
+	for i := 0; i < attempt; i++ {
+		if delay, ok = b.Next(); !ok {
+			return 0, false
+		}
+	}
+
+	return delay, true
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
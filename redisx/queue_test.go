@@ -0,0 +1,134 @@
+package redisx_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/msales/pkg/v5/redisx"
+	"github.com/msales/pkg/v5/retry"
+)
+
+func TestQueue_Run(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	q := redisx.NewQueue(client, "queue",
+		redisx.WithConcurrency(2),
+		redisx.WithPollInterval(time.Millisecond),
+	)
+
+	id1, err := q.Enqueue([]byte("job1"), 0)
+	assert.NoError(t, err)
+	id2, err := q.Enqueue([]byte("job2"), 10*time.Millisecond)
+	assert.NoError(t, err)
+
+	stats, err := q.Stats()
+	assert.NoError(t, err)
+	assert.Equal(t, redisx.QueueStats{Ready: 1, Delayed: 1}, stats)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	got := map[string]string{}
+	err = q.Run(ctx, func(ctx context.Context, job *redisx.Job) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Equal(t, 1, job.Attempt)
+		got[job.ID] = string(job.Payload)
+		if len(got) == 2 {
+			cancel()
+		}
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{id1: "job1", id2: "job2"}, got)
+
+	stats, err = q.Stats()
+	assert.NoError(t, err)
+	assert.Equal(t, redisx.QueueStats{}, stats)
+}
+
+func TestQueue_RunRetriesAndBuries(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	q := redisx.NewQueue(client, "queue",
+		redisx.WithPollInterval(time.Millisecond),
+		redisx.WithQueueRetry(retry.ExponentialPolicy(2, time.Millisecond)),
+	)
+
+	_, err := q.Enqueue([]byte("job"), 0)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var attempts []int
+	err = q.Run(ctx, func(ctx context.Context, job *redisx.Job) error {
+		attempts = append(attempts, job.Attempt)
+		if job.Attempt == 2 {
+			cancel()
+		}
+
+		return errors.New("test error")
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, attempts)
+
+	stats, err := q.Stats()
+	assert.NoError(t, err)
+	assert.Equal(t, redisx.QueueStats{Dead: 1}, stats)
+}
+
+func TestQueue_RunVisibilityTimeout(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	q := redisx.NewQueue(client, "queue",
+		redisx.WithConcurrency(2),
+		redisx.WithPollInterval(time.Millisecond),
+		redisx.WithVisibilityTimeout(20*time.Millisecond),
+	)
+
+	_, err := q.Enqueue([]byte("job"), 0)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var attempts []int
+	err = q.Run(ctx, func(ctx context.Context, job *redisx.Job) error {
+		mu.Lock()
+		attempts = append(attempts, job.Attempt)
+		mu.Unlock()
+
+		if job.Attempt == 1 {
+			// Outlive the visibility timeout, as if the worker was lost.
+			<-ctx.Done()
+			return ctx.Err()
+		}
+
+		cancel()
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, attempts)
+}
+
+func TestQueue_RunRedisError(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	client.Set("{queue}:ready", "value", 0)
+	q := redisx.NewQueue(client, "queue")
+
+	err := q.Run(context.Background(), func(ctx context.Context, job *redisx.Job) error {
+		return nil
+	})
+
+	assert.Error(t, err)
+}
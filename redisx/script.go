@@ -0,0 +1,126 @@
+package redisx
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis"
+)
+
+var (
+	// ErrCrossSlot means that the keys of a script do not hash to the same
+	// cluster slot.
+	ErrCrossSlot = errors.New("redisx: keys hash to different slots")
+
+	// ErrScriptNotFound means that no script is registered under the name.
+	ErrScriptNotFound = errors.New("redisx: script not found")
+)
+
+// Script represents a Lua script run by its SHA1 digest.
+type Script struct {
+	client Client
+	src    string
+	hash   string
+}
+
+// Hash returns the SHA1 digest of the script.
+func (s *Script) Hash() string {
+	return s.hash
+}
+
+// Run runs the script with EVALSHA, falling back to EVAL when the node does
+// not know the script, e.g. after a failover. All keys must hash to the same
+// cluster slot.
+func (s *Script) Run(keys []string, args ...interface{}) (interface{}, error) {
+	if !SameSlot(keys...) {
+		return nil, ErrCrossSlot
+	}
+
+	res, err := s.client.EvalSha(s.hash, keys, args...).Result()
+	if err != nil && strings.HasPrefix(err.Error(), "NOSCRIPT") {
+		return s.client.Eval(s.src, keys, args...).Result()
+	}
+
+	return res, err
+}
+
+// ScriptRegistry holds named Lua scripts and loads them on every node.
+type ScriptRegistry struct {
+	client Client
+
+	mu      sync.RWMutex
+	scripts map[string]*Script
+}
+
+// NewScriptRegistry returns a script registry using the given client.
+func NewScriptRegistry(c Client) *ScriptRegistry {
+	return &ScriptRegistry{
+		client:  c,
+		scripts: map[string]*Script{},
+	}
+}
+
+// Register adds the script under the name, replacing any previous one.
+func (r *ScriptRegistry) Register(name, src string) *Script {
+	sum := sha1.Sum([]byte(src))
+	s := &Script{
+		client: r.client,
+		src:    src,
+		hash:   hex.EncodeToString(sum[:]),
+	}
+
+	r.mu.Lock()
+	r.scripts[name] = s
+	r.mu.Unlock()
+
+	return s
+}
+
+// Script returns the script registered under the name.
+func (r *ScriptRegistry) Script(name string) (*Script, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.scripts[name]
+	return s, ok
+}
+
+// Load loads all registered scripts on every node, so that they can be run
+// with EVALSHA. It is usually called on startup.
+func (r *ScriptRegistry) Load() error {
+	r.mu.RLock()
+	scripts := make([]*Script, 0, len(r.scripts))
+	for _, s := range r.scripts {
+		scripts = append(scripts, s)
+	}
+	r.mu.RUnlock()
+
+	return r.client.ForEachNode(func(client *redis.Client) error {
+		for _, s := range scripts {
+			hash, err := client.ScriptLoad(s.src).Result()
+			if err != nil {
+				return err
+			}
+
+			if hash != s.hash {
+				return fmt.Errorf("redisx: unexpected script hash %s on %s", hash, client.Options().Addr)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Run runs the script registered under the name.
+func (r *ScriptRegistry) Run(name string, keys []string, args ...interface{}) (interface{}, error) {
+	s, ok := r.Script(name)
+	if !ok {
+		return nil, ErrScriptNotFound
+	}
+
+	return s.Run(keys, args...)
+}
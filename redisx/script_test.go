@@ -0,0 +1,66 @@
+package redisx_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/msales/pkg/v5/redisx"
+)
+
+func TestScriptRegistry_Load(t *testing.T) {
+	client1 := getClient()
+	client2 := getClient()
+
+	r := redisx.NewScriptRegistry(newNodesClientMock(client1, client2))
+	s := r.Register("incr", "return redis.call('INCRBY', KEYS[1], ARGV[1])")
+
+	err := r.Load()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true}, client1.ScriptExists(s.Hash()).Val())
+	assert.Equal(t, []bool{true}, client2.ScriptExists(s.Hash()).Val())
+}
+
+func TestScriptRegistry_Run(t *testing.T) {
+	client := redisx.NewClient(getClient())
+
+	r := redisx.NewScriptRegistry(client)
+	r.Register("incr", "return redis.call('INCRBY', KEYS[1], ARGV[1])")
+	assert.NoError(t, r.Load())
+
+	res, err := r.Run("incr", []string{"key"}, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), res)
+}
+
+func TestScriptRegistry_RunNoScript(t *testing.T) {
+	client := redisx.NewClient(getClient())
+
+	r := redisx.NewScriptRegistry(client)
+	s := r.Register("incr", "return redis.call('INCRBY', KEYS[1], ARGV[1])")
+
+	res, err := r.Run("incr", []string{"key"}, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), res)
+	assert.Equal(t, []bool{true}, client.ScriptExists(s.Hash()).Val())
+}
+
+func TestScriptRegistry_RunCrossSlot(t *testing.T) {
+	r := redisx.NewScriptRegistry(redisx.NewClient(getClient()))
+	r.Register("del", "return redis.call('DEL', KEYS[1], KEYS[2])")
+
+	_, err := r.Run("del", []string{"foo", "bar"})
+
+	assert.Equal(t, redisx.ErrCrossSlot, err)
+}
+
+func TestScriptRegistry_RunNotFound(t *testing.T) {
+	r := redisx.NewScriptRegistry(redisx.NewClient(getClient()))
+
+	_, err := r.Run("missing", []string{"key"})
+
+	assert.Equal(t, redisx.ErrScriptNotFound, err)
+}
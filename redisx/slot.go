@@ -0,0 +1,55 @@
+package redisx
+
+import "strings"
+
+// SlotCount is the number of hash slots in a redis cluster.
+const SlotCount = 16384
+
+// Slot returns the cluster hash slot of the key, honouring hash tags.
+func Slot(key string) int {
+	return int(crc16(hashTag(key)) % SlotCount)
+}
+
+// SameSlot reports whether all the keys hash to the same cluster slot.
+func SameSlot(keys ...string) bool {
+	for i := 1; i < len(keys); i++ {
+		if Slot(keys[i]) != Slot(keys[0]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hashTag returns the part of the key between the first "{" and the
+// following "}" when it is not empty, otherwise the key itself.
+func hashTag(key string) string {
+	s := strings.IndexByte(key, '{')
+	if s < 0 {
+		return key
+	}
+
+	e := strings.IndexByte(key[s+1:], '}')
+	if e <= 0 {
+		return key
+	}
+
+	return key[s+1 : s+1+e]
+}
+
+// crc16 implements the CRC16-CCITT (XModem) checksum used by redis cluster.
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
@@ -0,0 +1,38 @@
+package redisx_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/msales/pkg/v5/redisx"
+)
+
+func TestSlot(t *testing.T) {
+	tests := []struct {
+		key  string
+		slot int
+	}{
+		{key: "", slot: 0},
+		{key: "123456789", slot: 12739},
+		{key: "foo", slot: 12182},
+		{key: "{user1000}.following", slot: 3443},
+		{key: "{user1000}.followers", slot: 3443},
+		{key: "foo{}{bar}", slot: 8363},
+		{key: "foo{{bar}}zap", slot: 4015},
+		{key: "foo{bar}{zap}", slot: 5061},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.slot, redisx.Slot(tt.key))
+		})
+	}
+}
+
+func TestSameSlot(t *testing.T) {
+	assert.True(t, redisx.SameSlot())
+	assert.True(t, redisx.SameSlot("foo"))
+	assert.True(t, redisx.SameSlot("{user1000}.following", "{user1000}.followers"))
+	assert.False(t, redisx.SameSlot("foo", "bar"))
+}
@@ -0,0 +1,338 @@
+package redisx
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+
+	"github.com/msales/pkg/v5/retry"
+)
+
+// StreamHandler handles a single stream message. A message is acknowledged
+// once the handler returns nil.
+type StreamHandler func(ctx context.Context, msg redis.XMessage) error
+
+// ConsumerOptionsFunc represents a configuration function for a StreamConsumer.
+type ConsumerOptionsFunc func(*StreamConsumer)
+
+// WithStreamBatchSize configures the maximum number of messages read at once.
+func WithStreamBatchSize(size int64) ConsumerOptionsFunc {
+	return func(c *StreamConsumer) {
+		c.batchSize = size
+	}
+}
+
+// WithStreamBlock configures how long a read blocks waiting for new messages.
+// It also bounds how long Run takes to return after the context is done.
+func WithStreamBlock(block time.Duration) ConsumerOptionsFunc {
+	return func(c *StreamConsumer) {
+		c.block = block
+	}
+}
+
+// WithStreamMinIdle configures how long a message must be pending before it
+// is claimed from another consumer.
+func WithStreamMinIdle(idle time.Duration) ConsumerOptionsFunc {
+	return func(c *StreamConsumer) {
+		c.minIdle = idle
+	}
+}
+
+// WithStreamClaimInterval configures how often idle pending messages are claimed.
+func WithStreamClaimInterval(interval time.Duration) ConsumerOptionsFunc {
+	return func(c *StreamConsumer) {
+		c.claimInterval = interval
+	}
+}
+
+// WithStreamStartID configures the ID the consumer group starts from when
+// it is created. Use "$" to only consume new messages.
+func WithStreamStartID(id string) ConsumerOptionsFunc {
+	return func(c *StreamConsumer) {
+		c.startID = id
+	}
+}
+
+// WithStreamRetry configures the retry policy used for each handler call.
+func WithStreamRetry(policy retry.Policy) ConsumerOptionsFunc {
+	return func(c *StreamConsumer) {
+		c.policy = policy
+	}
+}
+
+// WithStreamErrorFunc configures a function called when a message could not
+// be handled. The message stays pending and is claimed again once idle.
+func WithStreamErrorFunc(fn func(msg redis.XMessage, err error)) ConsumerOptionsFunc {
+	return func(c *StreamConsumer) {
+		c.errFn = fn
+	}
+}
+
+// StreamConsumer consumes a redis stream as a member of a consumer group.
+type StreamConsumer struct {
+	client   Client
+	stream   string
+	group    string
+	consumer string
+	handler  StreamHandler
+
+	batchSize     int64
+	block         time.Duration
+	minIdle       time.Duration
+	claimInterval time.Duration
+	startID       string
+	policy        retry.Policy
+	errFn         func(msg redis.XMessage, err error)
+
+	claimStart  string
+	noAutoClaim bool
+	lastClaimed time.Time
+}
+
+// NewStreamConsumer returns a consumer of the stream for the given group and
+// consumer name.
+func NewStreamConsumer(c Client, stream, group, consumer string, h StreamHandler, opts ...ConsumerOptionsFunc) *StreamConsumer {
+	sc := &StreamConsumer{
+		client:        c,
+		stream:        stream,
+		group:         group,
+		consumer:      consumer,
+		handler:       h,
+		batchSize:     10,
+		block:         time.Second,
+		minIdle:       time.Minute,
+		claimInterval: 30 * time.Second,
+		startID:       "0",
+		errFn:         func(redis.XMessage, error) {},
+		claimStart:    "0-0",
+	}
+
+	for _, opt := range opts {
+		opt(sc)
+	}
+
+	return sc
+}
+
+// Run consumes messages until the context is done or a redis error occurs.
+// Idle messages of other consumers are claimed every claim interval.
+//
+// When the context is done, the message being handled is completed and the
+// rest of the batch is left pending to be claimed later. Run then returns nil.
+func (c *StreamConsumer) Run(ctx context.Context) error {
+	err := c.client.XGroupCreateMkStream(c.stream, c.group, c.startID).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+
+	for ctx.Err() == nil {
+		if time.Since(c.lastClaimed) >= c.claimInterval {
+			msgs, err := c.claim()
+			if err != nil {
+				return err
+			}
+			c.lastClaimed = time.Now()
+
+			if err := c.handle(ctx, msgs); err != nil {
+				return err
+			}
+		}
+
+		msgs, err := c.read()
+		if err != nil {
+			return err
+		}
+
+		if err := c.handle(ctx, msgs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *StreamConsumer) read() ([]redis.XMessage, error) {
+	streams, err := c.client.XReadGroup(&redis.XReadGroupArgs{
+		Group:    c.group,
+		Consumer: c.consumer,
+		Streams:  []string{c.stream, ">"},
+		Count:    c.batchSize,
+		Block:    c.block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var msgs []redis.XMessage
+	for _, s := range streams {
+		msgs = append(msgs, s.Messages...)
+	}
+
+	return msgs, nil
+}
+
+func (c *StreamConsumer) handle(ctx context.Context, msgs []redis.XMessage) error {
+	for _, msg := range msgs {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err := c.process(ctx, msg); err != nil {
+			c.errFn(msg, err)
+			continue
+		}
+
+		if err := c.client.XAck(c.stream, c.group, msg.ID).Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *StreamConsumer) process(ctx context.Context, msg redis.XMessage) error {
+	if c.policy == nil {
+		return c.handler(ctx, msg)
+	}
+
+	return retry.RunContext(ctx, c.policy, func(ctx context.Context, _ int) error {
+		return c.handler(ctx, msg)
+	})
+}
+
+// claim takes over messages that have been pending longer than the minimum
+// idle time, using XAUTOCLAIM where available and XPENDING otherwise.
+func (c *StreamConsumer) claim() ([]redis.XMessage, error) {
+	if !c.noAutoClaim {
+		msgs, err := c.autoClaim()
+		if err == nil || !isUnknownCommand(err) {
+			return msgs, err
+		}
+
+		c.noAutoClaim = true
+	}
+
+	pending, err := c.client.XPendingExt(&redis.XPendingExtArgs{
+		Stream: c.stream,
+		Group:  c.group,
+		Start:  c.claimStart,
+		End:    "+",
+		Count:  c.batchSize,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	// Move on through the pending list like XAUTOCLAIM does, so that
+	// messages held by live consumers do not hide idle ones behind them.
+	c.claimStart = "0-0"
+	if int64(len(pending)) == c.batchSize {
+		c.claimStart = nextStreamID(pending[len(pending)-1].Id)
+	}
+
+	ids := make([]string, 0, len(pending))
+	for _, p := range pending {
+		if p.Idle >= c.minIdle {
+			ids = append(ids, p.Id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return c.client.XClaim(&redis.XClaimArgs{
+		Stream:   c.stream,
+		Group:    c.group,
+		Consumer: c.consumer,
+		MinIdle:  c.minIdle,
+		Messages: ids,
+	}).Result()
+}
+
+func (c *StreamConsumer) autoClaim() ([]redis.XMessage, error) {
+	cmd := redis.NewSliceCmd(
+		"xautoclaim", c.stream, c.group, c.consumer,
+		int64(c.minIdle/time.Millisecond), c.claimStart,
+		"count", c.batchSize,
+	)
+	_ = c.client.Process(cmd)
+
+	res, err := cmd.Result()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) < 2 {
+		return nil, fmt.Errorf("redisx: unexpected xautoclaim reply %v", res)
+	}
+
+	c.claimStart, _ = res[0].(string)
+
+	return parseXMessages(res[1])
+}
+
+// nextStreamID returns the smallest stream ID following the given one.
+func nextStreamID(id string) string {
+	i := strings.IndexByte(id, '-')
+	if i < 0 {
+		return "0-0"
+	}
+
+	ms, err := strconv.ParseUint(id[:i], 10, 64)
+	if err != nil {
+		return "0-0"
+	}
+
+	seq, err := strconv.ParseUint(id[i+1:], 10, 64)
+	if err != nil {
+		return "0-0"
+	}
+
+	if seq == math.MaxUint64 {
+		return strconv.FormatUint(ms+1, 10) + "-0"
+	}
+
+	return strconv.FormatUint(ms, 10) + "-" + strconv.FormatUint(seq+1, 10)
+}
+
+func parseXMessages(v interface{}) ([]redis.XMessage, error) {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("redisx: unexpected messages reply %v", v)
+	}
+
+	msgs := make([]redis.XMessage, 0, len(items))
+	for _, item := range items {
+		// Messages deleted while pending are returned as nil.
+		entry, ok := item.([]interface{})
+		if !ok || len(entry) != 2 {
+			continue
+		}
+
+		id, _ := entry[0].(string)
+		fields, _ := entry[1].([]interface{})
+
+		values := make(map[string]interface{}, len(fields)/2)
+		for i := 0; i+1 < len(fields); i += 2 {
+			k, _ := fields[i].(string)
+			values[k] = fields[i+1]
+		}
+
+		msgs = append(msgs, redis.XMessage{ID: id, Values: values})
+	}
+
+	return msgs, nil
+}
+
+func isUnknownCommand(err error) bool {
+	return strings.HasPrefix(err.Error(), "ERR unknown command")
+}
@@ -0,0 +1,79 @@
+package redisx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamConsumer_ClaimXPendingMovesOn(t *testing.T) {
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	now := time.Now()
+	s.SetTime(now)
+
+	client := NewClient(redis.NewClient(&redis.Options{Addr: s.Addr()}))
+	client.XGroupCreateMkStream("stream", "group", "0")
+	var ids []string
+	for _, n := range []string{"1", "2", "3"} {
+		ids = append(ids, client.XAdd(&redis.XAddArgs{Stream: "stream", Values: map[string]interface{}{"n": n}}).Val())
+	}
+	client.XReadGroup(&redis.XReadGroupArgs{
+		Group:    "group",
+		Consumer: "crashed",
+		Streams:  []string{"stream", ">"},
+	})
+	s.SetTime(now.Add(time.Minute))
+
+	// The first messages are taken over by a live consumer.
+	client.XClaim(&redis.XClaimArgs{
+		Stream:   "stream",
+		Group:    "group",
+		Consumer: "live",
+		Messages: ids[:2],
+	})
+
+	c := NewStreamConsumer(client, "stream", "group", "consumer", func(context.Context, redis.XMessage) error {
+		return nil
+	},
+		WithStreamBatchSize(2),
+		WithStreamMinIdle(30*time.Second),
+	)
+	c.noAutoClaim = true
+
+	msgs, err := c.claim()
+	assert.NoError(t, err)
+	assert.Empty(t, msgs)
+
+	msgs, err = c.claim()
+	assert.NoError(t, err)
+	if assert.Len(t, msgs, 1) {
+		assert.Equal(t, ids[2], msgs[0].ID)
+	}
+	assert.Equal(t, "0-0", c.claimStart)
+}
+
+func TestNextStreamID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{id: "1-0", want: "1-1"},
+		{id: "1526919030474-55", want: "1526919030474-56"},
+		{id: "1-18446744073709551615", want: "2-0"},
+		{id: "invalid", want: "0-0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			assert.Equal(t, tt.want, nextStreamID(tt.id))
+		})
+	}
+}
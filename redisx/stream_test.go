@@ -0,0 +1,165 @@
+package redisx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/msales/pkg/v5/redisx"
+	"github.com/msales/pkg/v5/retry"
+)
+
+func TestStreamConsumer_Run(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	client.XAdd(&redis.XAddArgs{Stream: "stream", Values: map[string]interface{}{"n": "1"}})
+	client.XAdd(&redis.XAddArgs{Stream: "stream", Values: map[string]interface{}{"n": "2"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got []string
+	c := redisx.NewStreamConsumer(client, "stream", "group", "consumer", func(ctx context.Context, msg redis.XMessage) error {
+		got = append(got, msg.Values["n"].(string))
+		if len(got) == 2 {
+			cancel()
+		}
+
+		return nil
+	}, redisx.WithStreamBlock(10*time.Millisecond))
+
+	err := c.Run(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, got)
+	pending := client.XPending("stream", "group").Val()
+	assert.Equal(t, int64(0), pending.Count)
+}
+
+func TestStreamConsumer_RunRetries(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	client.XAdd(&redis.XAddArgs{Stream: "stream", Values: map[string]interface{}{"n": "1"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int
+	c := redisx.NewStreamConsumer(client, "stream", "group", "consumer", func(ctx context.Context, msg redis.XMessage) error {
+		calls++
+		if calls < 2 {
+			return errors.New("test error")
+		}
+
+		cancel()
+		return nil
+	},
+		redisx.WithStreamBlock(10*time.Millisecond),
+		redisx.WithStreamRetry(retry.ExponentialPolicy(3, time.Nanosecond)),
+	)
+
+	err := c.Run(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	pending, err := client.XPending("stream", "group").Result()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), pending.Count)
+}
+
+func TestStreamConsumer_RunRetriesCancelled(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	client.XAdd(&redis.XAddArgs{Stream: "stream", Values: map[string]interface{}{"n": "1"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int
+	c := redisx.NewStreamConsumer(client, "stream", "group", "consumer", func(ctx context.Context, msg redis.XMessage) error {
+		calls++
+		cancel()
+		return errors.New("test error")
+	},
+		redisx.WithStreamBlock(10*time.Millisecond),
+		redisx.WithStreamRetry(retry.ConstantPolicy(3, time.Hour)),
+	)
+
+	err := c.Run(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, int64(1), client.XPending("stream", "group").Val().Count)
+}
+
+func TestStreamConsumer_RunHandlerError(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	client.XAdd(&redis.XAddArgs{Stream: "stream", Values: map[string]interface{}{"n": "1"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var errs []error
+	c := redisx.NewStreamConsumer(client, "stream", "group", "consumer", func(ctx context.Context, msg redis.XMessage) error {
+		return errors.New("test error")
+	},
+		redisx.WithStreamBlock(10*time.Millisecond),
+		redisx.WithStreamErrorFunc(func(msg redis.XMessage, err error) {
+			errs = append(errs, err)
+			cancel()
+		}),
+	)
+
+	err := c.Run(ctx)
+
+	assert.NoError(t, err)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, int64(1), client.XPending("stream", "group").Val().Count)
+}
+
+func TestStreamConsumer_RunClaimsIdleMessages(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	client.XGroupCreateMkStream("stream", "group", "0")
+	client.XAdd(&redis.XAddArgs{Stream: "stream", Values: map[string]interface{}{"n": "1"}})
+	client.XReadGroup(&redis.XReadGroupArgs{
+		Group:    "group",
+		Consumer: "crashed",
+		Streams:  []string{"stream", ">"},
+	})
+	time.Sleep(5 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got []string
+	c := redisx.NewStreamConsumer(client, "stream", "group", "consumer", func(ctx context.Context, msg redis.XMessage) error {
+		got = append(got, msg.Values["n"].(string))
+		cancel()
+
+		return nil
+	},
+		redisx.WithStreamBlock(10*time.Millisecond),
+		redisx.WithStreamMinIdle(time.Millisecond),
+	)
+
+	err := c.Run(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1"}, got)
+	assert.Equal(t, int64(0), client.XPending("stream", "group").Val().Count)
+}
+
+func TestStreamConsumer_RunRedisError(t *testing.T) {
+	client := redisx.NewClient(getClient())
+	client.Set("stream", "value", 0)
+
+	c := redisx.NewStreamConsumer(client, "stream", "group", "consumer", func(ctx context.Context, msg redis.XMessage) error {
+		return nil
+	})
+
+	err := c.Run(context.Background())
+
+	assert.Error(t, err)
+}
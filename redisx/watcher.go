@@ -0,0 +1,246 @@
+package redisx
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis"
+)
+
+// ErrNotificationsDisabled means that keyspace notifications are not enabled
+// for the watched events on a node.
+var ErrNotificationsDisabled = errors.New("redisx: keyspace notifications disabled")
+
+// KeyEventType represents the type of a keyspace event.
+type KeyEventType string
+
+// KeyEventType values.
+const (
+	KeyExpired KeyEventType = "expired"
+	KeyEvicted KeyEventType = "evicted"
+	KeySet     KeyEventType = "set"
+	KeyDel     KeyEventType = "del"
+)
+
+// notifyFlags maps the event types to their notify-keyspace-events flag.
+var notifyFlags = map[KeyEventType]string{
+	KeyExpired: "x",
+	KeyEvicted: "e",
+	KeySet:     "$",
+	KeyDel:     "g",
+}
+
+// KeyEvent represents a keyspace event.
+type KeyEvent struct {
+	Type KeyEventType
+	Key  string
+}
+
+// KeyEventHandler handles a keyspace event.
+type KeyEventHandler func(event KeyEvent)
+
+// WatcherOptionsFunc represents a configuration function for a Watcher.
+type WatcherOptionsFunc func(*Watcher)
+
+// WithEnableNotifications configures the Watcher to enable the required
+// notify-keyspace-events flags on every node, instead of only validating them.
+func WithEnableNotifications() WatcherOptionsFunc {
+	return func(w *Watcher) {
+		w.enable = true
+	}
+}
+
+// WithoutConfigCheck configures the Watcher not to touch the node
+// configuration, e.g. when the CONFIG command is not available.
+func WithoutConfigCheck() WatcherOptionsFunc {
+	return func(w *Watcher) {
+		w.skipConfig = true
+	}
+}
+
+// WithWatcherSubscriberOptions configures the Subscriber of each node.
+func WithWatcherSubscriberOptions(opts ...SubscriberOptionsFunc) WatcherOptionsFunc {
+	return func(w *Watcher) {
+		w.subOpts = opts
+	}
+}
+
+// Watcher delivers keyspace events of every node to handlers.
+//
+// Keyspace notifications are not propagated between cluster nodes, so each
+// master is subscribed to separately.
+type Watcher struct {
+	client Client
+
+	enable     bool
+	skipConfig bool
+	subOpts    []SubscriberOptionsFunc
+
+	watches []watch
+}
+
+type watch struct {
+	pattern string
+	handler KeyEventHandler
+	types   map[KeyEventType]bool
+}
+
+// NewWatcher returns a keyspace watcher using the given client.
+func NewWatcher(c Client, opts ...WatcherOptionsFunc) *Watcher {
+	w := &Watcher{client: c}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Watch adds a handler for events of the given types on keys matching the
+// pattern. All types are delivered when none is given. Watch must be called
+// before Run.
+func (w *Watcher) Watch(pattern string, h KeyEventHandler, types ...KeyEventType) {
+	if len(types) == 0 {
+		types = []KeyEventType{KeyExpired, KeyEvicted, KeySet, KeyDel}
+	}
+
+	t := make(map[KeyEventType]bool, len(types))
+	for _, typ := range types {
+		t[typ] = true
+	}
+
+	w.watches = append(w.watches, watch{pattern: pattern, handler: h, types: t})
+}
+
+// Run validates or enables the notification config of every node, then
+// delivers events until the context is done.
+func (w *Watcher) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	nodes, err := collectNodes(w.client.ForEachNode)
+	if err != nil {
+		return err
+	}
+
+	if !w.skipConfig {
+		flags := w.flags()
+		for _, node := range nodes {
+			if err := w.configure(node, flags); err != nil {
+				return err
+			}
+		}
+	}
+
+	subs := make([]*Subscriber, 0, len(nodes))
+	defer func() {
+		for _, s := range subs {
+			_ = s.Close()
+		}
+	}()
+
+	for _, node := range nodes {
+		s := NewSubscriber(NewClient(node), w.subOpts...)
+		subs = append(subs, s)
+
+		prefix := "__keyspace@" + strconv.Itoa(node.Options().DB) + "__:"
+		for _, wt := range w.watches {
+			if err := s.PSubscribe(prefix+wt.pattern, wt.dispatch(prefix)); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Subscribers only return from Run once closed.
+	go func() {
+		<-ctx.Done()
+
+		for _, s := range subs {
+			_ = s.Close()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, s := range subs {
+		wg.Add(1)
+		go func(s *Subscriber) {
+			defer wg.Done()
+
+			_ = s.Run(ctx)
+		}(s)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// flags returns the notify-keyspace-events flags needed by the watches.
+func (w *Watcher) flags() string {
+	flags := "K"
+	for _, wt := range w.watches {
+		for typ := range wt.types {
+			if f := notifyFlags[typ]; !strings.Contains(flags, f) {
+				flags += f
+			}
+		}
+	}
+
+	return flags
+}
+
+func (w *Watcher) configure(node *redis.Client, flags string) error {
+	res, err := node.ConfigGet("notify-keyspace-events").Result()
+	if err != nil {
+		return err
+	}
+
+	var current string
+	if len(res) == 2 {
+		current, _ = res[1].(string)
+	}
+
+	missing := missingFlags(current, flags)
+	if missing == "" {
+		return nil
+	}
+
+	if !w.enable {
+		return ErrNotificationsDisabled
+	}
+
+	return node.ConfigSet("notify-keyspace-events", current+missing).Err()
+}
+
+// missingFlags returns the flags that are required but not set.
+func missingFlags(current, required string) string {
+	// "A" is an alias for all the event classes.
+	if strings.Contains(current, "A") {
+		current += "g$lshzxet"
+	}
+
+	var missing string
+	for _, f := range required {
+		if !strings.ContainsRune(current, f) {
+			missing += string(f)
+		}
+	}
+
+	return missing
+}
+
+func (wt watch) dispatch(prefix string) MessageHandler {
+	return func(msg *redis.Message) {
+		typ := KeyEventType(msg.Payload)
+		if !wt.types[typ] {
+			return
+		}
+
+		wt.handler(KeyEvent{
+			Type: typ,
+			Key:  strings.TrimPrefix(msg.Channel, prefix),
+		})
+	}
+}
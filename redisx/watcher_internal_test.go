@@ -0,0 +1,60 @@
+package redisx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  string
+		required string
+		missing  string
+	}{
+		{
+			name:     "disabled",
+			current:  "",
+			required: "Kx",
+			missing:  "Kx",
+		},
+		{
+			name:     "enabled",
+			current:  "Kx",
+			required: "Kx",
+			missing:  "",
+		},
+		{
+			name:     "partially enabled",
+			current:  "Ex$",
+			required: "Kx$g",
+			missing:  "Kg",
+		},
+		{
+			name:     "all events",
+			current:  "KA",
+			required: "Kx$ge",
+			missing:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.missing, missingFlags(tt.current, tt.required))
+		})
+	}
+}
+
+func TestWatcher_Flags(t *testing.T) {
+	w := NewWatcher(nil)
+	w.Watch("a*", func(KeyEvent) {}, KeyExpired)
+	w.Watch("b*", func(KeyEvent) {}, KeyExpired, KeyDel)
+
+	flags := w.flags()
+
+	assert.Len(t, flags, 3)
+	assert.Contains(t, flags, "K")
+	assert.Contains(t, flags, "x")
+	assert.Contains(t, flags, "g")
+}
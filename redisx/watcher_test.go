@@ -0,0 +1,83 @@
+package redisx_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/msales/pkg/v5/redisx"
+)
+
+func TestWatcher(t *testing.T) {
+	client1 := getClient()
+	client2 := getClient()
+
+	w := redisx.NewWatcher(newNodesClientMock(client1, client2), redisx.WithoutConfigCheck())
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	var mu sync.Mutex
+	var events []redisx.KeyEvent
+	w.Watch("user:*", func(event redisx.KeyEvent) {
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+		wg.Done()
+	}, redisx.KeyExpired, redisx.KeyDel)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, w.Run(ctx))
+	}()
+
+	// Redis publishes keyspace events itself, here they are simulated.
+	waitForSubscriber(t, client1, "__keyspace@0__:user:1")
+	waitForSubscriber(t, client2, "__keyspace@0__:user:2")
+	client1.Publish("__keyspace@0__:user:1", "set")
+	client1.Publish("__keyspace@0__:user:1", "expired")
+	client2.Publish("__keyspace@0__:other", "del")
+	client2.Publish("__keyspace@0__:user:2", "del")
+
+	wg.Wait()
+	cancel()
+	<-done
+
+	assert.ElementsMatch(t, []redisx.KeyEvent{
+		{Type: redisx.KeyExpired, Key: "user:1"},
+		{Type: redisx.KeyDel, Key: "user:2"},
+	}, events)
+}
+
+func TestWatcher_ConfigError(t *testing.T) {
+	w := redisx.NewWatcher(redisx.NewClient(getClient()))
+	w.Watch("user:*", func(event redisx.KeyEvent) {})
+
+	// miniredis does not support CONFIG.
+	err := w.Run(context.Background())
+
+	assert.Error(t, err)
+}
+
+func TestWatcher_WithError(t *testing.T) {
+	w := redisx.NewWatcher(&erroredNodesClientMock{getClient()})
+
+	err := w.Run(context.Background())
+
+	assert.Error(t, err)
+}
+
+// waitForSubscriber publishes an ignored event until the channel has a subscriber.
+func waitForSubscriber(t *testing.T, client *redis.Client, channel string) {
+	t.Helper()
+
+	waitFor(t, time.Second, func() bool {
+		return client.Publish(channel, "ignored").Val() > 0
+	})
+}
@@ -0,0 +1,95 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrBudgetExhausted is the reason of an *Error returned when a retry
+// was denied by the Budget.
+var ErrBudgetExhausted = errors.New("retry: budget exhausted")
+
+// BudgetStats represents the state of a Budget.
+type BudgetStats struct {
+	// Tokens is the number of retries currently allowed.
+	Tokens float64
+
+	// Allowed is the number of retries allowed so far.
+	Allowed int64
+
+	// Denied is the number of retries denied so far.
+	Denied int64
+}
+
+// Budget limits the retries of all runs sharing it, so retries cannot
+// multiply the load on a failing dependency.
+//
+// It is a token bucket: every successful run adds ratio tokens, every
+// retry takes one. With a ratio of 0.1, retries are limited to 10% of
+// the successful runs, and to maxTokens retries in a burst.
+type Budget struct {
+	ratio     float64
+	maxTokens float64
+
+	mu      sync.Mutex
+	tokens  float64
+	allowed int64
+	denied  int64
+}
+
+// NewBudget returns a full Budget with the given ratio of retries to
+// successful runs and the given maximum number of tokens.
+func NewBudget(ratio float64, maxTokens int) *Budget {
+	return &Budget{
+		ratio:     ratio,
+		maxTokens: float64(maxTokens),
+		tokens:    float64(maxTokens),
+	}
+}
+
+// Stats returns the current state of the Budget.
+func (b *Budget) Stats() BudgetStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return BudgetStats{
+		Tokens:  b.tokens,
+		Allowed: b.allowed,
+		Denied:  b.denied,
+	}
+}
+
+// deposit adds the tokens of a successful run.
+func (b *Budget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens += b.ratio
+	if b.tokens > b.maxTokens {
+		b.tokens = b.maxTokens
+	}
+}
+
+// withdraw takes the token of a retry, reporting whether it is allowed.
+func (b *Budget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens < 1 {
+		b.denied++
+		return false
+	}
+
+	b.tokens--
+	b.allowed++
+
+	return true
+}
+
+// WithBudget configures the Budget consulted before every retry. A denied
+// retry ends the run with an *Error whose reason is ErrBudgetExhausted.
+func WithBudget(b *Budget) RunOptionsFunc {
+	return func(o *runOptions) {
+		o.budget = b
+	}
+}
@@ -0,0 +1,77 @@
+package retry_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/msales/pkg/v5/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudget(t *testing.T) {
+	b := retry.NewBudget(0.5, 2)
+	p := retry.ExponentialPolicy(10, time.Nanosecond)
+	testErr := errors.New("test error")
+
+	var i int
+	err := retry.Run(p, func() error {
+		i++
+		return testErr
+	}, retry.WithBudget(b))
+
+	assert.True(t, errors.Is(err, retry.ErrBudgetExhausted))
+	assert.True(t, errors.Is(err, testErr))
+	assert.Equal(t, "retry: budget exhausted after 3 attempts, last error: test error", err.Error())
+	assert.Equal(t, 3, i)
+	assert.Equal(t, retry.BudgetStats{Tokens: 0, Allowed: 2, Denied: 1}, b.Stats())
+
+	for j := 0; j < 2; j++ {
+		err = retry.Run(p, func() error {
+			return nil
+		}, retry.WithBudget(b))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1.0, b.Stats().Tokens)
+
+	i = 0
+	err = retry.Run(p, func() error {
+		i++
+		if i < 2 {
+			return testErr
+		}
+
+		return nil
+	}, retry.WithBudget(b))
+
+	assert.NoError(t, err)
+	assert.Equal(t, retry.BudgetStats{Tokens: 0.5, Allowed: 3, Denied: 1}, b.Stats())
+}
+
+func TestBudget_MaxTokens(t *testing.T) {
+	b := retry.NewBudget(1, 2)
+
+	for i := 0; i < 5; i++ {
+		_ = retry.Run(retry.ExponentialPolicy(3, time.Nanosecond), func() error {
+			return nil
+		}, retry.WithBudget(b))
+	}
+
+	assert.Equal(t, 2.0, b.Stats().Tokens)
+}
+
+func TestBudget_GiveUp(t *testing.T) {
+	b := retry.NewBudget(0.1, 0)
+	c := &retry.Counters{}
+
+	var gaveUp bool
+	err := retry.Run(retry.ExponentialPolicy(3, time.Nanosecond), func() error {
+		return errors.New("test error")
+	}, retry.WithBudget(b), retry.WithMetrics(c), retry.OnGiveUp(func(int, error) {
+		gaveUp = true
+	}))
+
+	assert.True(t, errors.Is(err, retry.ErrBudgetExhausted))
+	assert.True(t, gaveUp)
+	assert.Equal(t, int64(1), c.Stats().Exhaustions)
+}
@@ -0,0 +1,21 @@
+package retry
+
+import "context"
+
+// Do executes the function like RunContext and returns the value of the
+// successful attempt. On error, the zero value is returned.
+func Do[T any](ctx context.Context, p Policy, fn func(ctx context.Context) (T, error), opts ...RunOptionsFunc) (T, error) {
+	var v T
+	err := RunContext(ctx, p, func(ctx context.Context, _ int) error {
+		var err error
+		v, err = fn(ctx)
+
+		return err
+	}, opts...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return v, nil
+}
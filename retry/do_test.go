@@ -0,0 +1,52 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/msales/pkg/v5/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDo(t *testing.T) {
+	var i int
+	v, err := retry.Do(context.Background(), retry.ExponentialPolicy(3, time.Nanosecond), func(ctx context.Context) (int, error) {
+		i++
+		if i < 2 {
+			return 0, errors.New("test error")
+		}
+
+		return 42, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 42, v)
+	assert.Equal(t, 2, i)
+}
+
+func TestDo_Exhausted(t *testing.T) {
+	testErr := errors.New("test error")
+
+	v, err := retry.Do(context.Background(), retry.ExponentialPolicy(3, time.Nanosecond), func(ctx context.Context) (string, error) {
+		return "partial", testErr
+	})
+
+	assert.True(t, errors.Is(err, testErr))
+	assert.Equal(t, "", v)
+}
+
+func TestDo_Stop(t *testing.T) {
+	testErr := errors.New("test error")
+
+	var i int
+	v, err := retry.Do(context.Background(), retry.ExponentialPolicy(3, time.Nanosecond), func(ctx context.Context) (*int, error) {
+		i++
+		return nil, retry.Stop(testErr)
+	})
+
+	assert.Equal(t, testErr, err)
+	assert.Nil(t, v)
+	assert.Equal(t, 1, i)
+}
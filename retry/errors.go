@@ -0,0 +1,107 @@
+package retry
+
+import (
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+)
+
+type stopError struct {
+	err error
+}
+
+// Stop wraps an error and stops retrying.
+func Stop(err error) error {
+	return &stopError{err: err}
+}
+
+// Error returns the error message.
+func (e *stopError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *stopError) Unwrap() error {
+	return e.err
+}
+
+// IsStop reports whether the error, or any error it wraps, was created
+// with Stop.
+func IsStop(err error) bool {
+	var s *stopError
+	return errors.As(err, &s)
+}
+
+// unwrapStop returns the error wrapped by Stop, unless the stop error
+// itself is wrapped, in which case the error is kept as is.
+func unwrapStop(err error) error {
+	if s, ok := err.(*stopError); ok {
+		return s.err
+	}
+
+	return err
+}
+
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+// RetryAfter wraps an error with the delay after which the next attempt
+// should be made, e.g. as suggested by a server.
+func RetryAfter(err error, delay time.Duration) error {
+	return &retryAfterError{
+		err:   err,
+		delay: delay,
+	}
+}
+
+// Error returns the error message.
+func (e *retryAfterError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *retryAfterError) Unwrap() error {
+	return e.err
+}
+
+// RetryAfter returns the delay after which the next attempt should be made.
+func (e *retryAfterError) RetryAfter() time.Duration {
+	return e.delay
+}
+
+// retryAfter returns the retry-after delay carried by the error, if any.
+// Errors of other packages can carry one by implementing
+// RetryAfter() time.Duration.
+func retryAfter(err error) (time.Duration, bool) {
+	var ra interface {
+		RetryAfter() time.Duration
+	}
+	if !errors.As(err, &ra) {
+		return 0, false
+	}
+
+	return ra.RetryAfter(), true
+}
+
+// IsTransient reports whether the error is a network error that is
+// likely to go away on retry, like a timeout or a reset connection.
+func IsTransient(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		switch errno {
+		case syscall.ECONNRESET, syscall.ECONNREFUSED, syscall.ECONNABORTED, syscall.EPIPE, syscall.ETIMEDOUT:
+			return true
+		}
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,208 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/msales/pkg/v5/clock"
+	"github.com/msales/pkg/v5/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsStop(t *testing.T) {
+	err := retry.Stop(errors.New("test error"))
+
+	assert.True(t, retry.IsStop(err))
+	assert.True(t, retry.IsStop(fmt.Errorf("wrapped: %w", err)))
+	assert.False(t, retry.IsStop(errors.New("test error")))
+	assert.False(t, retry.IsStop(nil))
+}
+
+func TestStop_Wrapped(t *testing.T) {
+	testErr := errors.New("test error")
+
+	var i int
+	err := retry.Run(retry.ExponentialPolicy(3, time.Nanosecond), func() error {
+		i++
+		return fmt.Errorf("wrapped: %w", retry.Stop(testErr))
+	})
+
+	assert.True(t, errors.Is(err, testErr))
+	assert.Equal(t, "wrapped: test error", err.Error())
+	assert.Equal(t, 1, i)
+}
+
+func TestRetryIf(t *testing.T) {
+	validationErr := errors.New("validation error")
+
+	var i int
+	err := retry.Run(retry.ExponentialPolicy(3, time.Nanosecond), func() error {
+		i++
+		if i == 2 {
+			return validationErr
+		}
+
+		return errors.New("test error")
+	}, retry.RetryIf(func(err error) bool {
+		return err != validationErr
+	}))
+
+	assert.Equal(t, validationErr, err)
+	assert.Equal(t, 2, i)
+}
+
+func TestRetryIf_Multiple(t *testing.T) {
+	var i int
+	err := retry.Run(retry.ExponentialPolicy(3, time.Nanosecond), func() error {
+		i++
+		return io.EOF
+	}, retry.RetryIf(retry.IsTransient), retry.RetryIf(func(error) bool { return false }))
+
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 1, i)
+}
+
+func TestRetryAfter(t *testing.T) {
+	testErr := errors.New("test error")
+
+	var i int
+	start := time.Now()
+	err := retry.RunContext(context.Background(), retry.ConstantPolicy(3, time.Hour), func(ctx context.Context, attempt int) error {
+		i++
+		if attempt == 1 {
+			return retry.RetryAfter(testErr, time.Millisecond)
+		}
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, i)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestRetryAfter_MaxDelay(t *testing.T) {
+	c := clock.NewFake(time.Now())
+	p := retry.WithMaxDelay(retry.ConstantPolicy(3, time.Millisecond), time.Second)
+
+	var sleeps []time.Duration
+	err := retry.RunContext(context.Background(), p, func(ctx context.Context, attempt int) error {
+		if attempt == 1 {
+			go func() {
+				c.BlockUntil(1)
+				c.Advance(time.Second)
+			}()
+
+			return retry.RetryAfter(errors.New("test error"), time.Hour)
+		}
+
+		return nil
+	},
+		retry.WithClock(c),
+		retry.OnRetry(func(attempt int, err error, delay time.Duration) {
+			sleeps = append(sleeps, delay)
+		}),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{time.Second}, sleeps)
+}
+
+func TestRetryAfter_MaxElapsed(t *testing.T) {
+	testErr := errors.New("test error")
+	c := clock.NewFake(time.Now())
+	p := retry.WithMaxElapsed(retry.ConstantPolicy(3, time.Millisecond), time.Minute, retry.WithMaxElapsedClock(c))
+
+	var i int
+	err := retry.RunContext(context.Background(), p, func(ctx context.Context, attempt int) error {
+		i++
+		return retry.RetryAfter(testErr, time.Hour)
+	}, retry.WithClock(c))
+
+	var retryErr *retry.Error
+	assert.True(t, errors.As(err, &retryErr))
+	assert.True(t, errors.Is(err, testErr))
+	assert.Equal(t, 1, i)
+}
+
+func TestRetryAfter_Wrappers(t *testing.T) {
+	c := clock.NewFake(time.Now())
+	p := retry.WithJitter(retry.WithMaxDelay(retry.WithMaxElapsed(retry.ConstantPolicy(3, time.Millisecond), time.Minute, retry.WithMaxElapsedClock(c)), time.Second))
+
+	var i int
+	err := retry.RunContext(context.Background(), p, func(ctx context.Context, attempt int) error {
+		i++
+		return retry.RetryAfter(errors.New("test error"), time.Hour)
+	}, retry.WithClock(c))
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, i)
+
+	p = retry.WithMaxElapsed(retry.WithMaxDelay(retry.ConstantPolicy(2, time.Millisecond), time.Second), time.Minute, retry.WithMaxElapsedClock(c))
+
+	var sleeps []time.Duration
+	err = retry.RunContext(context.Background(), p, func(ctx context.Context, attempt int) error {
+		if attempt == 1 {
+			go func() {
+				c.BlockUntil(1)
+				c.Advance(time.Second)
+			}()
+
+			return retry.RetryAfter(errors.New("test error"), time.Hour)
+		}
+
+		return nil
+	},
+		retry.WithClock(c),
+		retry.OnRetry(func(attempt int, err error, delay time.Duration) {
+			sleeps = append(sleeps, delay)
+		}),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{time.Second}, sleeps)
+}
+
+func TestRetryAfter_Unwrap(t *testing.T) {
+	testErr := errors.New("test error")
+	err := retry.RetryAfter(testErr, time.Second)
+
+	assert.True(t, errors.Is(err, testErr))
+	assert.Equal(t, "test error", err.Error())
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "eof", err: io.EOF, want: true},
+		{name: "unexpected eof", err: fmt.Errorf("read: %w", io.ErrUnexpectedEOF), want: true},
+		{name: "connection reset", err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, want: true},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, want: true},
+		{name: "timeout", err: timeoutError{}, want: true},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, want: true},
+		{name: "other", err: errors.New("test error"), want: false},
+		{name: "nil", err: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, retry.IsTransient(tt.err))
+		})
+	}
+}
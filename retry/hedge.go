@@ -0,0 +1,134 @@
+package retry
+
+import (
+	"context"
+	"time"
+
+	"github.com/msales/pkg/v5/clock"
+)
+
+// HedgeOptionsFunc represents a configuration function for Hedge.
+type HedgeOptionsFunc func(*hedgeOptions)
+
+type hedgeOptions struct {
+	clock clock.Clock
+}
+
+// WithHedgeClock configures the clock the attempts are delayed with.
+func WithHedgeClock(c clock.Clock) HedgeOptionsFunc {
+	return func(o *hedgeOptions) {
+		o.clock = c
+	}
+}
+
+// Hedge calls the function and, while no attempt has succeeded, calls it
+// again every delay, up to maxAttempts concurrent attempts. The value of
+// the first successful attempt is returned with its attempt number; the
+// contexts of the other attempts are cancelled.
+func Hedge[T any](ctx context.Context, delay time.Duration, maxAttempts int, fn func(ctx context.Context, attempt int) (T, error), opts ...HedgeOptionsFunc) (T, int, error) {
+	return HedgePolicy(ctx, ConstantPolicy(maxAttempts, delay), fn, opts...)
+}
+
+// HedgePolicy is like Hedge, starting a new attempt after every sleep of
+// the Policy until it gives up.
+//
+// A failed attempt starts the next one right away. When all attempts fail,
+// an *Error holding their errors is returned; an attempt returning Stop
+// ends hedging with its error.
+func HedgePolicy[T any](ctx context.Context, p Policy, fn func(ctx context.Context, attempt int) (T, error), opts ...HedgeOptionsFunc) (T, int, error) {
+	var zero T
+	if p == nil {
+		return zero, 0, errNilPolicy
+	}
+
+	o := &hedgeOptions{clock: clock.Real}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		v       T
+		attempt int
+		err     error
+	}
+	results := make(chan result)
+
+	var attempt, running int
+	start := func() {
+		attempt++
+		running++
+
+		go func(attempt int) {
+			v, err := fn(ctx, attempt)
+
+			select {
+			case results <- result{v: v, attempt: attempt, err: err}:
+			case <-ctx.Done():
+			}
+		}(attempt)
+	}
+
+	b := p.Backoff()
+	more := true
+	var timer clock.Timer
+	var next <-chan time.Time
+	stopTimer := func() {
+		if timer != nil {
+			timer.Stop()
+		}
+		next = nil
+	}
+	defer stopTimer()
+
+	var errs []error
+	start()
+	for {
+		if err := ctx.Err(); err != nil {
+			return zero, 0, err
+		}
+
+		if more && next == nil {
+			delay, ok := b.Next()
+			if ok {
+				timer = o.clock.NewTimer(delay)
+				next = timer.C()
+			} else {
+				more = false
+			}
+		}
+
+		select {
+		case r := <-results:
+			running--
+			if r.err == nil {
+				return r.v, r.attempt, nil
+			}
+
+			if IsStop(r.err) {
+				return zero, 0, unwrapStop(r.err)
+			}
+
+			errs = append(errs, r.err)
+
+			if next != nil {
+				stopTimer()
+				start()
+				continue
+			}
+
+			if running == 0 {
+				return zero, 0, &Error{Errors: errs}
+			}
+
+		case <-next:
+			next = nil
+			start()
+
+		case <-ctx.Done():
+			return zero, 0, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/msales/pkg/v5/clock"
+	"github.com/msales/pkg/v5/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHedge(t *testing.T) {
+	cancelled := make(chan struct{})
+	v, attempt, err := retry.Hedge(context.Background(), time.Millisecond, 3, func(ctx context.Context, attempt int) (string, error) {
+		if attempt == 1 {
+			<-ctx.Done()
+			close(cancelled)
+			return "", ctx.Err()
+		}
+
+		return "value", nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+	assert.Equal(t, 2, attempt)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		assert.Fail(t, "losing attempt not cancelled")
+	}
+}
+
+func TestHedge_FirstWins(t *testing.T) {
+	var calls int32
+	v, attempt, err := retry.Hedge(context.Background(), time.Hour, 3, func(ctx context.Context, attempt int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return 42, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 42, v)
+	assert.Equal(t, 1, attempt)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestHedge_FailureStartsNextAttempt(t *testing.T) {
+	v, attempt, err := retry.Hedge(context.Background(), time.Hour, 3, func(ctx context.Context, attempt int) (int, error) {
+		if attempt == 1 {
+			return 0, errors.New("test error")
+		}
+
+		return attempt, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+	assert.Equal(t, 2, attempt)
+}
+
+func TestHedge_AllFail(t *testing.T) {
+	testErr := errors.New("test error")
+
+	var calls int32
+	v, attempt, err := retry.Hedge(context.Background(), time.Millisecond, 3, func(ctx context.Context, attempt int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return attempt, testErr
+	})
+
+	var retryErr *retry.Error
+	assert.True(t, errors.As(err, &retryErr))
+	assert.Len(t, retryErr.Errors, 3)
+	assert.Zero(t, v)
+	assert.Zero(t, attempt)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestHedge_Stop(t *testing.T) {
+	testErr := errors.New("test error")
+
+	_, _, err := retry.Hedge(context.Background(), time.Hour, 3, func(ctx context.Context, attempt int) (int, error) {
+		return 0, retry.Stop(testErr)
+	})
+
+	assert.Equal(t, testErr, err)
+}
+
+func TestHedge_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	_, _, err := retry.Hedge(ctx, time.Hour, 3, func(ctx context.Context, attempt int) (int, error) {
+		cancel()
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestHedge_Clock(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+
+	var calls int32
+	v, attempt, err := retry.Hedge(context.Background(), time.Minute, 2, func(ctx context.Context, attempt int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		if attempt == 1 {
+			clk.BlockUntil(1)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+			clk.Advance(time.Minute)
+
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+
+		return attempt, nil
+	}, retry.WithHedgeClock(clk))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+	assert.Equal(t, 2, attempt)
+}
+
+func TestHedgePolicy(t *testing.T) {
+	var calls int32
+	_, _, err := retry.HedgePolicy(context.Background(), retry.LinearPolicy(4, time.Millisecond), func(ctx context.Context, attempt int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return 0, errors.New("test error")
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+}
+
+func TestHedgePolicy_NilPolicy(t *testing.T) {
+	_, _, err := retry.HedgePolicy(context.Background(), nil, func(ctx context.Context, attempt int) (int, error) {
+		return 0, nil
+	})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package retry
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// OnRetry configures a function called after a failed attempt, before
+// sleeping for the given delay.
+func OnRetry(fn func(attempt int, err error, delay time.Duration)) RunOptionsFunc {
+	return func(o *runOptions) {
+		o.onRetry = fn
+	}
+}
+
+// OnGiveUp configures a function called when the Policy gives up, with
+// the number of attempts made and the returned error.
+func OnGiveUp(fn func(attempts int, err error)) RunOptionsFunc {
+	return func(o *runOptions) {
+		o.onGiveUp = fn
+	}
+}
+
+// WithMetrics configures the Metrics the outcome of the run is counted in.
+func WithMetrics(m Metrics) RunOptionsFunc {
+	return func(o *runOptions) {
+		o.metrics = m
+	}
+}
+
+// Metrics counts the outcome of runs.
+type Metrics interface {
+	// Attempt is called before every attempt.
+	Attempt()
+
+	// SuccessAfterRetry is called when a run succeeds after at least
+	// one failed attempt.
+	SuccessAfterRetry(attempts int)
+
+	// Exhausted is called when the Policy gives up.
+	Exhausted(attempts int)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) Attempt()              {}
+func (nopMetrics) SuccessAfterRetry(int) {}
+func (nopMetrics) Exhausted(int)         {}
+
+// CounterStats represents the values of Counters.
+type CounterStats struct {
+	Attempts            int64
+	SuccessesAfterRetry int64
+	Exhaustions         int64
+}
+
+// Counters is Metrics counting in memory. It is safe for concurrent use
+// and can be shared between runs.
+type Counters struct {
+	attempts            int64
+	successesAfterRetry int64
+	exhaustions         int64
+}
+
+// Attempt counts an attempt.
+func (c *Counters) Attempt() {
+	atomic.AddInt64(&c.attempts, 1)
+}
+
+// SuccessAfterRetry counts a success after retry.
+func (c *Counters) SuccessAfterRetry(int) {
+	atomic.AddInt64(&c.successesAfterRetry, 1)
+}
+
+// Exhausted counts an exhaustion.
+func (c *Counters) Exhausted(int) {
+	atomic.AddInt64(&c.exhaustions, 1)
+}
+
+// Stats returns the current counts.
+func (c *Counters) Stats() CounterStats {
+	return CounterStats{
+		Attempts:            atomic.LoadInt64(&c.attempts),
+		SuccessesAfterRetry: atomic.LoadInt64(&c.successesAfterRetry),
+		Exhaustions:         atomic.LoadInt64(&c.exhaustions),
+	}
+}
@@ -0,0 +1,84 @@
+package retry_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/msales/pkg/v5/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnRetry(t *testing.T) {
+	testErr := errors.New("test error")
+
+	type call struct {
+		attempt int
+		err     error
+		delay   time.Duration
+	}
+	var calls []call
+	var i int
+	err := retry.Run(retry.ExponentialPolicy(3, time.Nanosecond), func() error {
+		i++
+		if i < 3 {
+			return testErr
+		}
+
+		return nil
+	}, retry.OnRetry(func(attempt int, err error, delay time.Duration) {
+		calls = append(calls, call{attempt: attempt, err: err, delay: delay})
+	}), retry.OnGiveUp(func(int, error) {
+		assert.Fail(t, "unexpected give up")
+	}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []call{
+		{attempt: 1, err: testErr, delay: time.Nanosecond},
+		{attempt: 2, err: testErr, delay: 2 * time.Nanosecond},
+	}, calls)
+}
+
+func TestOnGiveUp(t *testing.T) {
+	var attempts int
+	var giveUpErr error
+	err := retry.Run(retry.ExponentialPolicy(3, time.Nanosecond), func() error {
+		return errors.New("test error")
+	}, retry.OnGiveUp(func(n int, err error) {
+		attempts = n
+		giveUpErr = err
+	}))
+
+	assert.Error(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, err, giveUpErr)
+}
+
+func TestCounters(t *testing.T) {
+	c := &retry.Counters{}
+	p := retry.ExponentialPolicy(3, time.Nanosecond)
+
+	_ = retry.Run(p, func() error {
+		return nil
+	}, retry.WithMetrics(c))
+
+	var i int
+	_ = retry.Run(p, func() error {
+		i++
+		if i < 2 {
+			return errors.New("test error")
+		}
+
+		return nil
+	}, retry.WithMetrics(c))
+
+	_ = retry.Run(p, func() error {
+		return errors.New("test error")
+	}, retry.WithMetrics(c))
+
+	assert.Equal(t, retry.CounterStats{
+		Attempts:            6,
+		SuccessesAfterRetry: 1,
+		Exhaustions:         1,
+	}, c.Stats())
+}
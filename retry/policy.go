@@ -0,0 +1,325 @@
+package retry
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/msales/pkg/v5/clock"
+)
+
+type constantPolicy struct {
+	attempts int
+	sleep    time.Duration
+}
+
+// ConstantPolicy retries with the same sleep between attempts.
+func ConstantPolicy(attempts int, sleep time.Duration) Policy {
+	return constantPolicy{
+		attempts: attempts,
+		sleep:    sleep,
+	}
+}
+
+func (p constantPolicy) Backoff() Backoff {
+	return &constantBackoff{
+		attempts: p.attempts,
+		sleep:    p.sleep,
+	}
+}
+
+type constantBackoff struct {
+	attempts int
+	sleep    time.Duration
+}
+
+func (b *constantBackoff) Next() (time.Duration, bool) {
+	b.attempts--
+	if b.attempts <= 0 {
+		return 0, false
+	}
+
+	return b.sleep, true
+}
+
+type linearPolicy struct {
+	attempts int
+	sleep    time.Duration
+}
+
+// LinearPolicy retries with a linear growth in sleep.
+func LinearPolicy(attempts int, sleep time.Duration) Policy {
+	return linearPolicy{
+		attempts: attempts,
+		sleep:    sleep,
+	}
+}
+
+func (p linearPolicy) Backoff() Backoff {
+	return &linearBackoff{
+		attempts: p.attempts,
+		sleep:    p.sleep,
+	}
+}
+
+type linearBackoff struct {
+	attempts int
+	sleep    time.Duration
+	next     time.Duration
+}
+
+func (b *linearBackoff) Next() (time.Duration, bool) {
+	b.attempts--
+	if b.attempts <= 0 {
+		return 0, false
+	}
+
+	b.next += b.sleep
+
+	return b.next, true
+}
+
+// FullJitterPolicy retries with an exponential growth in sleep, picking
+// a random sleep between zero and the exponential one.
+func FullJitterPolicy(attempts int, sleep time.Duration) Policy {
+	return WithJitter(ExponentialPolicy(attempts, sleep))
+}
+
+// EqualJitterPolicy retries with an exponential growth in sleep, keeping
+// half of the exponential sleep and picking the other half at random.
+func EqualJitterPolicy(attempts int, sleep time.Duration) Policy {
+	return mapPolicy{
+		p: ExponentialPolicy(attempts, sleep),
+		fn: func(sleep time.Duration) time.Duration {
+			return sleep/2 + randDuration(sleep-sleep/2)
+		},
+	}
+}
+
+type decorrelatedJitterPolicy struct {
+	attempts int
+	base     time.Duration
+	max      time.Duration
+}
+
+// DecorrelatedJitterPolicy retries with a random sleep between the base
+// sleep and three times the previous one, capped at max.
+func DecorrelatedJitterPolicy(attempts int, base, max time.Duration) Policy {
+	return decorrelatedJitterPolicy{
+		attempts: attempts,
+		base:     base,
+		max:      max,
+	}
+}
+
+func (p decorrelatedJitterPolicy) Backoff() Backoff {
+	return &decorrelatedJitterBackoff{
+		attempts: p.attempts,
+		base:     p.base,
+		max:      p.max,
+		sleep:    p.base,
+	}
+}
+
+type decorrelatedJitterBackoff struct {
+	attempts int
+	base     time.Duration
+	max      time.Duration
+	sleep    time.Duration
+}
+
+func (b *decorrelatedJitterBackoff) Next() (time.Duration, bool) {
+	b.attempts--
+	if b.attempts <= 0 {
+		return 0, false
+	}
+
+	b.sleep = b.base + randDuration(3*b.sleep-b.base)
+	if b.sleep > b.max {
+		b.sleep = b.max
+	}
+
+	return b.sleep, true
+}
+
+// WithJitter wraps the Policy, picking a random sleep between zero and
+// the sleep of the Policy.
+func WithJitter(p Policy) Policy {
+	return mapPolicy{
+		p:  p,
+		fn: randDuration,
+	}
+}
+
+// WithMaxDelay wraps the Policy, capping its sleep at max. Sleeps that
+// are not positive, as from an overflowing Policy, are capped as well.
+func WithMaxDelay(p Policy, max time.Duration) Policy {
+	return maxDelayPolicy{
+		p:   p,
+		max: max,
+	}
+}
+
+type maxDelayPolicy struct {
+	p   Policy
+	max time.Duration
+}
+
+func (p maxDelayPolicy) Backoff() Backoff {
+	return &maxDelayBackoff{
+		b:   p.p.Backoff(),
+		max: p.max,
+	}
+}
+
+type maxDelayBackoff struct {
+	b   Backoff
+	max time.Duration
+}
+
+func (b *maxDelayBackoff) Next() (time.Duration, bool) {
+	sleep, ok := b.b.Next()
+	if !ok {
+		return 0, false
+	}
+
+	if sleep <= 0 || sleep > b.max {
+		return b.max, true
+	}
+
+	return sleep, true
+}
+
+func (b *maxDelayBackoff) limit(sleep time.Duration) (time.Duration, bool) {
+	sleep, ok := limit(b.b, sleep)
+	if !ok {
+		return 0, false
+	}
+
+	if sleep > b.max {
+		return b.max, true
+	}
+
+	return sleep, true
+}
+
+// mapPolicy wraps a Policy, changing every sleep with a function.
+type mapPolicy struct {
+	p  Policy
+	fn func(time.Duration) time.Duration
+}
+
+func (p mapPolicy) Backoff() Backoff {
+	return &mapBackoff{
+		b:  p.p.Backoff(),
+		fn: p.fn,
+	}
+}
+
+type mapBackoff struct {
+	b  Backoff
+	fn func(time.Duration) time.Duration
+}
+
+func (b *mapBackoff) Next() (time.Duration, bool) {
+	sleep, ok := b.b.Next()
+	if !ok {
+		return 0, false
+	}
+
+	return b.fn(sleep), true
+}
+
+func (b *mapBackoff) limit(sleep time.Duration) (time.Duration, bool) {
+	return limit(b.b, sleep)
+}
+
+type maxElapsedPolicy struct {
+	p     Policy
+	d     time.Duration
+	clock clock.Clock
+}
+
+// MaxElapsedOptionsFunc represents a configuration function for WithMaxElapsed.
+type MaxElapsedOptionsFunc func(*maxElapsedPolicy)
+
+// WithMaxElapsedClock configures the clock the elapsed time is measured with.
+func WithMaxElapsedClock(c clock.Clock) MaxElapsedOptionsFunc {
+	return func(p *maxElapsedPolicy) {
+		p.clock = c
+	}
+}
+
+// WithMaxElapsed wraps the Policy, stopping once the next attempt would
+// start later than the given duration after the run started.
+func WithMaxElapsed(p Policy, d time.Duration, opts ...MaxElapsedOptionsFunc) Policy {
+	mp := maxElapsedPolicy{
+		p:     p,
+		d:     d,
+		clock: clock.Real,
+	}
+
+	for _, opt := range opts {
+		opt(&mp)
+	}
+
+	return mp
+}
+
+func (p maxElapsedPolicy) Backoff() Backoff {
+	return &maxElapsedBackoff{
+		b:        p.p.Backoff(),
+		clock:    p.clock,
+		deadline: p.clock.Now().Add(p.d),
+	}
+}
+
+type maxElapsedBackoff struct {
+	b        Backoff
+	clock    clock.Clock
+	deadline time.Time
+}
+
+func (b *maxElapsedBackoff) Next() (time.Duration, bool) {
+	sleep, ok := b.b.Next()
+	if !ok || b.clock.Now().Add(sleep).After(b.deadline) {
+		return 0, false
+	}
+
+	return sleep, true
+}
+
+func (b *maxElapsedBackoff) limit(sleep time.Duration) (time.Duration, bool) {
+	sleep, ok := limit(b.b, sleep)
+	if !ok || b.clock.Now().Add(sleep).After(b.deadline) {
+		return 0, false
+	}
+
+	return sleep, true
+}
+
+// limiter is implemented by Backoffs that limit their sleeps, so that
+// sleeps not coming from the Backoff, like retry-after delays, can be
+// held to the same limits.
+type limiter interface {
+	// limit returns the sleep capped to the limits, or false if it
+	// exceeds a limit that cannot be capped.
+	limit(sleep time.Duration) (time.Duration, bool)
+}
+
+// limit holds the sleep to the limits of the Backoff, if any.
+func limit(b Backoff, sleep time.Duration) (time.Duration, bool) {
+	if l, ok := b.(limiter); ok {
+		return l.limit(sleep)
+	}
+
+	return sleep, true
+}
+
+// randDuration returns a random duration in [0, d).
+func randDuration(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(d)))
+}
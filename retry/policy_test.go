@@ -0,0 +1,226 @@
+package retry_test
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msales/pkg/v5/clock"
+	"github.com/msales/pkg/v5/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstantPolicy(t *testing.T) {
+	p := retry.ConstantPolicy(3, time.Millisecond).Backoff()
+
+	assertSleeps(t, p, time.Millisecond, time.Millisecond)
+}
+
+func TestLinearPolicy(t *testing.T) {
+	p := retry.LinearPolicy(4, time.Millisecond).Backoff()
+
+	assertSleeps(t, p, time.Millisecond, 2*time.Millisecond, 3*time.Millisecond)
+}
+
+func TestFullJitterPolicy(t *testing.T) {
+	p := retry.FullJitterPolicy(4, time.Millisecond).Backoff()
+
+	for _, max := range []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond} {
+		sleep, ok := p.Next()
+		assert.True(t, ok)
+		assert.True(t, sleep >= 0 && sleep < max)
+	}
+
+	sleep, ok := p.Next()
+	assert.False(t, ok)
+	assert.Zero(t, sleep)
+}
+
+func TestEqualJitterPolicy(t *testing.T) {
+	p := retry.EqualJitterPolicy(4, time.Millisecond).Backoff()
+
+	for _, max := range []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond} {
+		sleep, ok := p.Next()
+		assert.True(t, ok)
+		assert.True(t, sleep >= max/2 && sleep < max)
+	}
+
+	sleep, ok := p.Next()
+	assert.False(t, ok)
+	assert.Zero(t, sleep)
+}
+
+func TestDecorrelatedJitterPolicy(t *testing.T) {
+	p := retry.DecorrelatedJitterPolicy(10, time.Millisecond, 5*time.Millisecond).Backoff()
+
+	prev := time.Millisecond
+	for i := 0; i < 9; i++ {
+		sleep, ok := p.Next()
+		assert.True(t, ok)
+		assert.True(t, sleep >= time.Millisecond)
+		assert.True(t, sleep <= 5*time.Millisecond)
+		assert.True(t, sleep < 3*prev)
+		prev = sleep
+	}
+
+	sleep, ok := p.Next()
+	assert.False(t, ok)
+	assert.Zero(t, sleep)
+}
+
+func TestWithJitter(t *testing.T) {
+	p := retry.WithJitter(retry.ConstantPolicy(3, time.Millisecond)).Backoff()
+
+	for i := 0; i < 2; i++ {
+		sleep, ok := p.Next()
+		assert.True(t, ok)
+		assert.True(t, sleep >= 0 && sleep < time.Millisecond)
+	}
+
+	sleep, ok := p.Next()
+	assert.False(t, ok)
+	assert.Zero(t, sleep)
+}
+
+func TestWithMaxDelay(t *testing.T) {
+	p := retry.WithMaxDelay(retry.ExponentialPolicy(5, time.Millisecond), 3*time.Millisecond).Backoff()
+
+	assertSleeps(t, p, time.Millisecond, 2*time.Millisecond, 3*time.Millisecond, 3*time.Millisecond)
+}
+
+func TestWithMaxDelay_NonPositive(t *testing.T) {
+	p := retry.WithMaxDelay(retry.ConstantPolicy(3, -time.Second), time.Second).Backoff()
+
+	assertSleeps(t, p, time.Second, time.Second)
+}
+
+func TestExponentialPolicy_Saturates(t *testing.T) {
+	p := retry.ExponentialPolicy(100, time.Second).Backoff()
+
+	prev := time.Duration(0)
+	for i := 0; i < 99; i++ {
+		sleep, ok := p.Next()
+		assert.True(t, ok)
+		assert.True(t, sleep >= prev, "attempt %d: %s after %s", i+1, sleep, prev)
+		prev = sleep
+	}
+	assert.Equal(t, time.Duration(math.MaxInt64), prev)
+}
+
+func TestWithMaxDelay_HighAttempts(t *testing.T) {
+	p := retry.WithMaxDelay(retry.ExponentialPolicy(100, time.Second), 30*time.Second).Backoff()
+
+	for i := 0; i < 99; i++ {
+		sleep, ok := p.Next()
+		assert.True(t, ok)
+		if i >= 5 {
+			assert.Equal(t, 30*time.Second, sleep, "attempt %d", i+1)
+		}
+	}
+}
+
+func TestJitterPolicies_HighAttempts(t *testing.T) {
+	policies := []retry.Policy{
+		retry.FullJitterPolicy(100, time.Second),
+		retry.EqualJitterPolicy(100, time.Second),
+	}
+
+	for _, policy := range policies {
+		p := policy.Backoff()
+		for i := 0; i < 99; i++ {
+			sleep, ok := p.Next()
+			assert.True(t, ok)
+			assert.True(t, sleep >= 0, "attempt %d: %s", i+1, sleep)
+		}
+	}
+
+	p := retry.WithMaxDelay(retry.FullJitterPolicy(100, time.Second), 30*time.Second).Backoff()
+	for i := 0; i < 99; i++ {
+		sleep, _ := p.Next()
+		assert.True(t, sleep <= 30*time.Second, "attempt %d: %s", i+1, sleep)
+	}
+}
+
+func TestWithMaxElapsed(t *testing.T) {
+	p := retry.WithMaxElapsed(retry.ConstantPolicy(3, time.Millisecond), time.Hour).Backoff()
+
+	assertSleeps(t, p, time.Millisecond, time.Millisecond)
+}
+
+func TestWithMaxElapsed_Exceeded(t *testing.T) {
+	p := retry.WithMaxElapsed(retry.ConstantPolicy(3, time.Hour), time.Minute).Backoff()
+
+	sleep, ok := p.Next()
+	assert.False(t, ok)
+	assert.Zero(t, sleep)
+}
+
+func TestWithMaxElapsed_Clock(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	p := retry.WithMaxElapsed(retry.ConstantPolicy(5, time.Second), 3*time.Second, retry.WithMaxElapsedClock(clk)).Backoff()
+
+	sleep, ok := p.Next()
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, sleep)
+
+	clk.Advance(2 * time.Second)
+	sleep, ok = p.Next()
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, sleep)
+
+	clk.Advance(time.Second)
+	sleep, ok = p.Next()
+	assert.False(t, ok)
+	assert.Zero(t, sleep)
+}
+
+func TestPolicy_Reuse(t *testing.T) {
+	p := retry.ExponentialPolicy(3, time.Millisecond)
+
+	assertSleeps(t, p.Backoff(), time.Millisecond, 2*time.Millisecond)
+	assertSleeps(t, p.Backoff(), time.Millisecond, 2*time.Millisecond)
+}
+
+func TestPolicy_Concurrent(t *testing.T) {
+	p := retry.WithMaxElapsed(retry.ExponentialPolicy(3, time.Nanosecond), time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var n int
+			_ = retry.Run(p, func() error {
+				n++
+				return errors.New("test error")
+			})
+			assert.Equal(t, 3, n)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPolicyFunc(t *testing.T) {
+	p := retry.PolicyFunc(func() retry.Backoff {
+		return retry.ConstantPolicy(2, time.Millisecond).Backoff()
+	})
+
+	assertSleeps(t, p.Backoff(), time.Millisecond)
+}
+
+func assertSleeps(t *testing.T, b retry.Backoff, sleeps ...time.Duration) {
+	t.Helper()
+
+	for _, want := range sleeps {
+		sleep, ok := b.Next()
+		assert.True(t, ok)
+		assert.Equal(t, want, sleep)
+	}
+
+	sleep, ok := b.Next()
+	assert.False(t, ok)
+	assert.Zero(t, sleep)
+}
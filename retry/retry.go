@@ -1,15 +1,40 @@
 package retry
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math"
 	"time"
+
+	"github.com/msales/pkg/v5/clock"
 )
 
+var errNilPolicy = errors.New("policy must not be nil")
+
 // Policy determines how Run retries the function.
+//
+// A Policy holds no state of its own, so it can be shared between
+// goroutines and reused; every run uses a new Backoff.
 type Policy interface {
+	Backoff() Backoff
+}
+
+// Backoff holds the retry state of a single run.
+type Backoff interface {
+	// Next returns the sleep before the next attempt, or false when
+	// no attempts are left.
 	Next() (time.Duration, bool)
 }
 
+// PolicyFunc is a function that returns a new Backoff for every run.
+type PolicyFunc func() Backoff
+
+// Backoff returns a new Backoff.
+func (fn PolicyFunc) Backoff() Backoff {
+	return fn()
+}
+
 type exponentialPolicy struct {
 	attempts int
 	sleep    time.Duration
@@ -17,53 +42,235 @@ type exponentialPolicy struct {
 
 // ExponentialPolicy retires with an exponential growth in sleep.
 func ExponentialPolicy(attempts int, sleep time.Duration) Policy {
-	return &exponentialPolicy{
+	return exponentialPolicy{
 		attempts: attempts,
 		sleep:    sleep,
 	}
 }
 
-func (p *exponentialPolicy) Next() (time.Duration, bool) {
-	p.attempts--
-	if p.attempts <= 0 {
+func (p exponentialPolicy) Backoff() Backoff {
+	return &exponentialBackoff{
+		attempts: p.attempts,
+		sleep:    p.sleep,
+	}
+}
+
+type exponentialBackoff struct {
+	attempts int
+	sleep    time.Duration
+}
+
+func (b *exponentialBackoff) Next() (time.Duration, bool) {
+	b.attempts--
+	if b.attempts <= 0 {
 		return 0, false
 	}
 
 	defer func() {
-		p.sleep *= 2
+		// Saturate instead of overflowing into negative sleeps.
+		if b.sleep > math.MaxInt64/2 {
+			b.sleep = math.MaxInt64
+			return
+		}
+		b.sleep *= 2
 	}()
 
-	return p.sleep, true
+	return b.sleep, true
+}
+
+// RunOptionsFunc represents a configuration function for Run.
+type RunOptionsFunc func(*runOptions)
+
+type runOptions struct {
+	retryIf  []func(error) bool
+	onRetry  func(attempt int, err error, delay time.Duration)
+	onGiveUp func(attempts int, err error)
+	metrics  Metrics
+	budget   *Budget
+	clock    clock.Clock
+}
+
+// RetryIf configures a predicate deciding whether an error is retried.
+// An error not matching it is returned without further attempts. When
+// given multiple times, an error is retried only if all predicates match.
+func RetryIf(fn func(error) bool) RunOptionsFunc {
+	return func(o *runOptions) {
+		o.retryIf = append(o.retryIf, fn)
+	}
+}
+
+// WithClock configures the clock used to sleep between attempts.
+func WithClock(c clock.Clock) RunOptionsFunc {
+	return func(o *runOptions) {
+		o.clock = c
+	}
+}
+
+func (o *runOptions) retryable(err error) bool {
+	for _, fn := range o.retryIf {
+		if !fn(err) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (o *runOptions) giveUp(attempts int, err error) error {
+	o.metrics.Exhausted(attempts)
+	o.onGiveUp(attempts, err)
+
+	return err
 }
 
 // Run executes the function while the Policy allows
 // until it returns nil or Stop.
-func Run(p Policy, fn func() error) error {
+func Run(p Policy, fn func() error, opts ...RunOptionsFunc) error {
+	return RunContext(context.Background(), p, func(context.Context, int) error {
+		return fn()
+	}, opts...)
+}
+
+// RunContext executes the function while the Policy allows
+// until it returns nil or Stop, or the context is done.
+//
+// The attempt number, starting at 1, is passed to the function. When the
+// Policy gives up, an *Error holding the errors of all attempts is
+// returned. When the context is done after a failed attempt, the *Error
+// also holds the context error as its Reason.
+//
+// An error carrying a retry-after delay, see RetryAfter, is retried after
+// that delay instead of the one of the Policy. The delay is still held to
+// the limits of WithMaxDelay and WithMaxElapsed.
+func RunContext(ctx context.Context, p Policy, fn func(ctx context.Context, attempt int) error, opts ...RunOptionsFunc) error {
 	if p == nil {
-		return errors.New("policy must not be nil")
+		return errNilPolicy
+	}
+
+	o := &runOptions{
+		onRetry:  func(int, error, time.Duration) {},
+		onGiveUp: func(int, error) {},
+		metrics:  nopMetrics{},
+		clock:    clock.Real,
 	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	b := p.Backoff()
+
+	var errs []error
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			if len(errs) == 0 {
+				return err
+			}
+			return &Error{Errors: errs, Reason: err}
+		}
+
+		o.metrics.Attempt()
+
+		err := fn(ctx, attempt)
+		if err == nil {
+			if attempt > 1 {
+				o.metrics.SuccessAfterRetry(attempt)
+			}
+			if o.budget != nil {
+				o.budget.deposit()
+			}
+			return nil
+		}
+
+		if IsStop(err) {
+			return unwrapStop(err)
+		}
 
-	if err := fn(); err != nil {
-		if s, ok := err.(stop); ok {
-			return s.error
+		if !o.retryable(err) {
+			return err
 		}
 
-		if sleep, ok := p.Next(); ok {
-			time.Sleep(sleep)
-			Run(p, fn)
+		errs = append(errs, err)
+
+		sleep, ok := b.Next()
+		if !ok {
+			return o.giveUp(attempt, &Error{Errors: errs})
+		}
+
+		if d, ok := retryAfter(err); ok {
+			sleep, ok = limit(b, d)
+			if !ok {
+				return o.giveUp(attempt, &Error{Errors: errs})
+			}
+		}
+
+		if o.budget != nil && !o.budget.withdraw() {
+			return o.giveUp(attempt, &Error{Errors: errs, Reason: ErrBudgetExhausted})
 		}
 
-		return err
+		o.onRetry(attempt, err, sleep)
+
+		if cerr := clock.Sleep(ctx, o.clock, sleep); cerr != nil {
+			return &Error{Errors: errs, Reason: cerr}
+		}
+	}
+}
+
+// Error is returned when the retries are exhausted or stopped.
+type Error struct {
+	// Errors holds the error of every attempt, in order.
+	Errors []error
+
+	// Reason is why retrying stopped before the Policy gave up,
+	// like ErrBudgetExhausted or the context error, if any.
+	Reason error
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	if e.Reason != nil {
+		return fmt.Sprintf("%v after %d attempts, last error: %v", e.Reason, len(e.Errors), e.Unwrap())
 	}
 
-	return nil
+	return fmt.Sprintf("retry: %d attempts failed, last error: %v", len(e.Errors), e.Unwrap())
 }
 
-type stop struct {
-	error
+// Unwrap returns the error of the last attempt.
+func (e *Error) Unwrap() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+
+	return e.Errors[len(e.Errors)-1]
+}
+
+// Is reports whether the reason or the error of any attempt matches
+// the target.
+func (e *Error) Is(target error) bool {
+	if e.Reason != nil && errors.Is(e.Reason, target) {
+		return true
+	}
+
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
 }
 
-// Stop wraps an error and stops retrying.
-func Stop(err error) error {
-	return stop{err}
+// As finds the first of the reason and the attempt errors that matches
+// the target, and if so, sets the target to that error.
+func (e *Error) As(target interface{}) bool {
+	if e.Reason != nil && errors.As(e.Reason, target) {
+		return true
+	}
+
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
 }
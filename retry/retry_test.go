@@ -1,16 +1,21 @@
 package retry_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/msales/pkg/v5/clock"
 	"github.com/msales/pkg/v5/retry"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestExponentialPolicy(t *testing.T) {
-	p := retry.ExponentialPolicy(3, time.Millisecond)
+	p := retry.ExponentialPolicy(3, time.Millisecond).Backoff()
 
 	sleep, ok := p.Next()
 	assert.True(t, ok)
@@ -56,6 +61,73 @@ func TestRun_MaxAttempts(t *testing.T) {
 	assert.Equal(t, 3, i)
 }
 
+func TestRun_SucceedsAfterRetry(t *testing.T) {
+	var i int
+	err := retry.Run(retry.ExponentialPolicy(3, time.Nanosecond), func() error {
+		i++
+		if i < 2 {
+			return errors.New("test error")
+		}
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, i)
+}
+
+func TestRun_Exhausted(t *testing.T) {
+	errs := []error{errors.New("error 1"), errors.New("error 2"), errors.New("error 3")}
+
+	var i int
+	err := retry.Run(retry.ExponentialPolicy(3, time.Nanosecond), func() error {
+		i++
+		return errs[i-1]
+	})
+
+	var retryErr *retry.Error
+	assert.True(t, errors.As(err, &retryErr))
+	assert.Equal(t, errs, retryErr.Errors)
+	assert.Equal(t, errs[2], errors.Unwrap(err))
+	assert.Equal(t, "retry: 3 attempts failed, last error: error 3", err.Error())
+}
+
+func TestRun_StopInLaterAttempt(t *testing.T) {
+	testErr := errors.New("test error")
+
+	var i int
+	err := retry.Run(retry.ExponentialPolicy(3, time.Nanosecond), func() error {
+		i++
+		if i == 2 {
+			return retry.Stop(testErr)
+		}
+
+		return errors.New("other error")
+	})
+
+	assert.Equal(t, testErr, err)
+	assert.Equal(t, 2, i)
+}
+
+func TestError_Is(t *testing.T) {
+	err1 := errors.New("error 1")
+	err2 := errors.New("error 2")
+	err := &retry.Error{Errors: []error{err1, fmt.Errorf("wrapped: %w", err2)}}
+
+	assert.True(t, errors.Is(err, err1))
+	assert.True(t, errors.Is(err, err2))
+	assert.False(t, errors.Is(err, errors.New("error 3")))
+}
+
+func TestError_As(t *testing.T) {
+	pathErr := &os.PathError{Op: "open", Path: "test", Err: errors.New("test error")}
+	err := &retry.Error{Errors: []error{pathErr, errors.New("error 2")}}
+
+	var target *os.PathError
+	assert.True(t, errors.As(err, &target))
+	assert.Equal(t, pathErr, target)
+}
+
 func TestStop(t *testing.T) {
 	var i int
 	retry.Run(retry.ExponentialPolicy(3, time.Nanosecond), func() error {
@@ -65,3 +137,138 @@ func TestStop(t *testing.T) {
 
 	assert.Equal(t, 1, i)
 }
+
+func TestRunContext(t *testing.T) {
+	var attempts []int
+	err := retry.RunContext(context.Background(), retry.ExponentialPolicy(3, time.Nanosecond), func(ctx context.Context, attempt int) error {
+		attempts = append(attempts, attempt)
+		if attempt < 2 {
+			return errors.New("test error")
+		}
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, attempts)
+}
+
+func TestRunContext_NilPolicy(t *testing.T) {
+	err := retry.RunContext(context.Background(), nil, func(ctx context.Context, attempt int) error {
+		return nil
+	})
+	assert.Error(t, err)
+}
+
+func TestRunContext_MaxAttempts(t *testing.T) {
+	var i int
+	testErr := errors.New("test error")
+	err := retry.RunContext(context.Background(), retry.ExponentialPolicy(3, time.Nanosecond), func(ctx context.Context, attempt int) error {
+		i++
+		return testErr
+	})
+
+	assert.True(t, errors.Is(err, testErr))
+	assert.Equal(t, 3, i)
+}
+
+func TestRunContext_Stop(t *testing.T) {
+	var i int
+	testErr := errors.New("test error")
+	err := retry.RunContext(context.Background(), retry.ExponentialPolicy(3, time.Nanosecond), func(ctx context.Context, attempt int) error {
+		i++
+		return retry.Stop(testErr)
+	})
+
+	assert.Equal(t, testErr, err)
+	assert.Equal(t, 1, i)
+}
+
+func TestRunContext_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var i int
+	testErr := errors.New("test error")
+	start := time.Now()
+	err := retry.RunContext(ctx, retry.ExponentialPolicy(3, time.Hour), func(ctx context.Context, attempt int) error {
+		i++
+		cancel()
+		return testErr
+	})
+
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.True(t, errors.Is(err, testErr))
+	assert.Contains(t, err.Error(), "test error")
+	assert.Equal(t, 1, i)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestRunContext_CancelAfterSleep(t *testing.T) {
+	testErr := errors.New("test error")
+	ctx := &lateCancelContext{Context: context.Background()}
+
+	var i int
+	err := retry.RunContext(ctx, retry.ExponentialPolicy(3, time.Nanosecond), func(ctx context.Context, attempt int) error {
+		i++
+		atomic.StoreInt32(&ctx.(*lateCancelContext).cancelled, 1)
+		return testErr
+	})
+
+	var retryErr *retry.Error
+	assert.True(t, errors.As(err, &retryErr))
+	assert.Equal(t, context.Canceled, retryErr.Reason)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.True(t, errors.Is(err, testErr))
+	assert.Equal(t, 1, i)
+}
+
+func TestRunContext_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var i int
+	err := retry.RunContext(ctx, retry.ExponentialPolicy(3, time.Nanosecond), func(ctx context.Context, attempt int) error {
+		i++
+		return nil
+	})
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 0, i)
+}
+
+func TestRunContext_WithClock(t *testing.T) {
+	c := clock.NewFake(time.Now())
+
+	done := make(chan error)
+	var attempts int
+	go func() {
+		done <- retry.RunContext(context.Background(), retry.ExponentialPolicy(3, time.Hour), func(ctx context.Context, attempt int) error {
+			attempts = attempt
+			return errors.New("test error")
+		}, retry.WithClock(c))
+	}()
+
+	c.BlockUntil(1)
+	c.Advance(time.Hour)
+	c.BlockUntil(1)
+	c.Advance(2 * time.Hour)
+
+	assert.Error(t, <-done)
+	assert.Equal(t, 3, attempts)
+}
+
+// lateCancelContext is a context cancelled without closing its Done
+// channel, as if it was cancelled just after a sleep finished.
+type lateCancelContext struct {
+	context.Context
+
+	cancelled int32
+}
+
+func (c *lateCancelContext) Err() error {
+	if atomic.LoadInt32(&c.cancelled) == 1 {
+		return context.Canceled
+	}
+
+	return nil
+}
@@ -0,0 +1,266 @@
+package syncx
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/msales/pkg/v5/clock"
+)
+
+// GroupOptionsFunc represents a configuration function for a Group.
+type GroupOptionsFunc func(*groupOptions)
+
+type groupOptions struct {
+	ttl   time.Duration
+	clock clock.Clock
+}
+
+// WithResultTTL configures how long a successful result is kept and returned
+// to later callers of the same key. By default results are not kept.
+func WithResultTTL(ttl time.Duration) GroupOptionsFunc {
+	return func(o *groupOptions) {
+		o.ttl = ttl
+	}
+}
+
+// WithGroupClock configures the clock the result TTL is measured with.
+func WithGroupClock(c clock.Clock) GroupOptionsFunc {
+	return func(o *groupOptions) {
+		o.clock = c
+	}
+}
+
+// Group coalesces concurrent calls for the same key into a single execution
+// whose result is shared by all callers.
+//
+// The zero value is ready to use and keeps no results.
+type Group[T any] struct {
+	opts groupOptions
+
+	mu      sync.Mutex
+	calls   map[string]*call[T]
+	results map[string]result[T]
+	swept   time.Time
+}
+
+type call[T any] struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	dups    int
+	forgot  bool
+
+	val   T
+	err   error
+	panic *panicError
+}
+
+// panicError is a panic recovered from the function of a call, re-raised
+// in the callers waiting for it.
+type panicError struct {
+	value interface{}
+	stack []byte
+}
+
+func (p *panicError) Error() string {
+	return fmt.Sprintf("%v\n\n%s", p.value, p.stack)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (p *panicError) Unwrap() error {
+	err, _ := p.value.(error)
+	return err
+}
+
+type result[T any] struct {
+	val     T
+	expires time.Time
+}
+
+// NewGroup returns a Group.
+func NewGroup[T any](opts ...GroupOptionsFunc) *Group[T] {
+	g := &Group[T]{}
+
+	for _, opt := range opts {
+		opt(&g.opts)
+	}
+
+	return g
+}
+
+// Do executes the function for the key, unless an execution is already in
+// flight, in which case the caller waits for its result. The shared flag
+// reports whether the result was given to more than one caller.
+//
+// The function runs with a context carrying the values of the first
+// caller's context, which is cancelled only once every waiting caller has
+// given up. A caller whose context is done returns its error right away.
+//
+// A panic in the function is recovered and raised again in every caller
+// waiting for its result.
+func (g *Group[T]) Do(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (v T, shared bool, err error) {
+	g.mu.Lock()
+	if r, ok := g.result(key); ok {
+		g.mu.Unlock()
+		return r.val, true, nil
+	}
+
+	if c, ok := g.calls[key]; ok {
+		c.waiters++
+		c.dups++
+		g.mu.Unlock()
+
+		return g.wait(ctx, key, c)
+	}
+
+	cctx, cancel := context.WithCancel(valuesContext{ctx})
+	c := &call[T]{
+		done:    make(chan struct{}),
+		cancel:  cancel,
+		waiters: 1,
+	}
+	if g.calls == nil {
+		g.calls = map[string]*call[T]{}
+	}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	go g.run(cctx, key, c, fn)
+
+	return g.wait(ctx, key, c)
+}
+
+// Forget forgets the key, so that the next call executes the function
+// instead of waiting for an execution in flight or using a kept result.
+func (g *Group[T]) Forget(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.forget(key)
+}
+
+func (g *Group[T]) run(ctx context.Context, key string, c *call[T], fn func(ctx context.Context) (T, error)) {
+	defer c.cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			c.panic = &panicError{value: r, stack: debug.Stack()}
+		}
+
+		g.mu.Lock()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		if c.err == nil && c.panic == nil && !c.forgot && g.opts.ttl > 0 {
+			g.store(key, c.val)
+		}
+		g.mu.Unlock()
+
+		close(c.done)
+	}()
+
+	c.val, c.err = fn(ctx)
+}
+
+func (g *Group[T]) wait(ctx context.Context, key string, c *call[T]) (T, bool, error) {
+	select {
+	case <-c.done:
+		g.mu.Lock()
+		shared := c.dups > 0
+		g.mu.Unlock()
+
+		if c.panic != nil {
+			panic(c.panic)
+		}
+
+		return c.val, shared, c.err
+
+	case <-ctx.Done():
+		g.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			// Nobody waits for the result anymore.
+			if g.calls[key] == c {
+				g.forget(key)
+			}
+			c.cancel()
+		}
+		g.mu.Unlock()
+
+		var zero T
+		return zero, false, ctx.Err()
+	}
+}
+
+// forget removes the call and result of the key. The lock must be held.
+func (g *Group[T]) forget(key string) {
+	if c, ok := g.calls[key]; ok {
+		c.forgot = true
+		delete(g.calls, key)
+	}
+	delete(g.results, key)
+}
+
+// result returns the unexpired result of the key. The lock must be held.
+func (g *Group[T]) result(key string) (result[T], bool) {
+	r, ok := g.results[key]
+	if !ok {
+		return result[T]{}, false
+	}
+
+	if !g.now().Before(r.expires) {
+		delete(g.results, key)
+		return result[T]{}, false
+	}
+
+	return r, true
+}
+
+// store keeps the result of the key, removing expired results at most once
+// per TTL. The lock must be held.
+func (g *Group[T]) store(key string, val T) {
+	now := g.now()
+
+	if g.results == nil {
+		g.results = map[string]result[T]{}
+	}
+
+	if now.Sub(g.swept) >= g.opts.ttl {
+		g.swept = now
+		for k, r := range g.results {
+			if !now.Before(r.expires) {
+				delete(g.results, k)
+			}
+		}
+	}
+
+	g.results[key] = result[T]{val: val, expires: now.Add(g.opts.ttl)}
+}
+
+func (g *Group[T]) now() time.Time {
+	if g.opts.clock == nil {
+		return time.Now()
+	}
+
+	return g.opts.clock.Now()
+}
+
+// valuesContext keeps the values of a context, but not its deadline
+// or cancellation.
+type valuesContext struct {
+	context.Context
+}
+
+func (valuesContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (valuesContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (valuesContext) Err() error {
+	return nil
+}
@@ -0,0 +1,129 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup_DoDuplicates(t *testing.T) {
+	var g Group[int]
+	var calls int32
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			v, shared, err := g.Do(context.Background(), "key", fn)
+			assert.NoError(t, err)
+			assert.Equal(t, 42, v)
+			assert.True(t, shared)
+		}()
+	}
+
+	waitForWaiters(t, &g, "key", 10)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestGroup_DoCallerCancel(t *testing.T) {
+	var g Group[string]
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (string, error) {
+		started <- struct{}{}
+		<-release
+		return "value", ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, _, err := g.Do(ctx, "key", fn)
+		first <- err
+	}()
+	<-started
+
+	second := make(chan string)
+	go func() {
+		v, _, err := g.Do(context.Background(), "key", fn)
+		assert.NoError(t, err)
+		second <- v
+	}()
+	waitForWaiters(t, &g, "key", 2)
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-first)
+
+	close(release)
+	assert.Equal(t, "value", <-second)
+	assert.Len(t, started, 0)
+}
+
+func TestGroup_DoPanicDuplicates(t *testing.T) {
+	var g Group[int]
+	testErr := errors.New("test error")
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		<-release
+		panic(testErr)
+	}
+
+	panics := make(chan interface{}, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			defer func() {
+				panics <- recover()
+			}()
+
+			_, _, _ = g.Do(context.Background(), "key", fn)
+		}()
+	}
+
+	waitForWaiters(t, &g, "key", 3)
+	close(release)
+
+	for i := 0; i < 3; i++ {
+		err, ok := (<-panics).(error)
+		assert.True(t, ok)
+		assert.True(t, errors.Is(err, testErr))
+	}
+}
+
+// waitForWaiters waits until n callers wait for the execution of the key.
+func waitForWaiters[T any](t *testing.T, g *Group[T], key string, n int) {
+	t.Helper()
+
+	waiters := func() int {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		if c, ok := g.calls[key]; ok {
+			return c.waiters
+		}
+		return 0
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for waiters() < n {
+		if time.Now().After(deadline) {
+			assert.FailNow(t, "callers did not join in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
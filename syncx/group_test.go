@@ -0,0 +1,173 @@
+package syncx_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/msales/pkg/v5/clock"
+	"github.com/msales/pkg/v5/syncx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup_Do(t *testing.T) {
+	var g syncx.Group[string]
+
+	v, shared, err := g.Do(context.Background(), "key", func(ctx context.Context) (string, error) {
+		return "value", nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+	assert.False(t, shared)
+}
+
+func TestGroup_DoError(t *testing.T) {
+	var g syncx.Group[string]
+	testErr := errors.New("test error")
+
+	_, _, err := g.Do(context.Background(), "key", func(ctx context.Context) (string, error) {
+		return "", testErr
+	})
+
+	assert.Equal(t, testErr, err)
+}
+
+func TestGroup_DoPanic(t *testing.T) {
+	var g syncx.Group[string]
+	testErr := errors.New("test error")
+
+	assert.Panics(t, func() {
+		_, _, _ = g.Do(context.Background(), "key", func(ctx context.Context) (string, error) {
+			panic(testErr)
+		})
+	})
+
+	v, _, err := g.Do(context.Background(), "key", func(ctx context.Context) (string, error) {
+		return "value", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+}
+
+func TestGroup_DoAllCallersCancel(t *testing.T) {
+	var g syncx.Group[string]
+	cancelled := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(time.Millisecond)
+		cancel()
+	}()
+
+	_, _, err := g.Do(ctx, "key", func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return "", ctx.Err()
+	})
+
+	assert.Equal(t, context.Canceled, err)
+	<-cancelled
+
+	v, _, err := g.Do(context.Background(), "key", func(ctx context.Context) (string, error) {
+		return "value", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+}
+
+func TestGroup_DoContextValues(t *testing.T) {
+	type ctxKey struct{}
+	var g syncx.Group[string]
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), ctxKey{}, "value"), time.Hour)
+	defer cancel()
+
+	v, _, err := g.Do(ctx, "key", func(ctx context.Context) (string, error) {
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+
+		return ctx.Value(ctxKey{}).(string), nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+}
+
+func TestGroup_Forget(t *testing.T) {
+	var g syncx.Group[int]
+	var calls int32
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		n := atomic.AddInt32(&calls, 1)
+		started <- struct{}{}
+		<-release
+		return int(n), nil
+	}
+
+	first := make(chan int)
+	go func() {
+		v, _, _ := g.Do(context.Background(), "key", fn)
+		first <- v
+	}()
+	<-started
+
+	g.Forget("key")
+
+	second := make(chan int)
+	go func() {
+		v, _, _ := g.Do(context.Background(), "key", fn)
+		second <- v
+	}()
+	<-started
+
+	close(release)
+	assert.ElementsMatch(t, []int{1, 2}, []int{<-first, <-second})
+}
+
+func TestGroup_ResultTTL(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	g := syncx.NewGroup[int](syncx.WithResultTTL(time.Minute), syncx.WithGroupClock(clk))
+
+	var calls int
+	fn := func(ctx context.Context) (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	v, shared, err := g.Do(context.Background(), "key", fn)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	assert.False(t, shared)
+
+	v, shared, err = g.Do(context.Background(), "key", fn)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	assert.True(t, shared)
+
+	clk.Advance(time.Minute)
+	v, _, _ = g.Do(context.Background(), "key", fn)
+	assert.Equal(t, 2, v)
+
+	g.Forget("key")
+	v, _, _ = g.Do(context.Background(), "key", fn)
+	assert.Equal(t, 3, v)
+}
+
+func TestGroup_ResultTTLSkipsErrors(t *testing.T) {
+	g := syncx.NewGroup[int](syncx.WithResultTTL(time.Minute))
+
+	var calls int
+	fn := func(ctx context.Context) (int, error) {
+		calls++
+		return 0, errors.New("test error")
+	}
+
+	_, _, _ = g.Do(context.Background(), "key", fn)
+	_, _, _ = g.Do(context.Background(), "key", fn)
+
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,156 @@
+package syncx
+
+import (
+	"context"
+	"hash/maphash"
+	"sync"
+)
+
+// KeyedMutex is a set of mutexes, one per key, so that only one goroutine
+// at a time holds the lock of a key. The mutex of a key is removed once it
+// is neither held nor waited for.
+//
+// The zero value is ready to use.
+type KeyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	mu   Mutex
+	refs int
+}
+
+// Lock locks the key.
+// If the key is already locked, the calling goroutine
+// blocks until it is available.
+func (k *KeyedMutex) Lock(key string) {
+	l := k.acquire(key)
+	l.mu.Lock()
+}
+
+// Unlock unlocks the key.
+// It is a run-time error if the key is not locked on entry to Unlock.
+func (k *KeyedMutex) Unlock(key string) {
+	k.mu.Lock()
+	l, ok := k.locks[key]
+	k.mu.Unlock()
+	if !ok {
+		panic("syncx: unlock of unlocked key")
+	}
+
+	l.mu.Unlock()
+	k.release(key, l)
+}
+
+// TryLock tries to lock the key. It returns true in case of success,
+// false otherwise.
+func (k *KeyedMutex) TryLock(key string) bool {
+	l := k.acquire(key)
+	if l.mu.TryLock() {
+		return true
+	}
+
+	k.release(key, l)
+	return false
+}
+
+// LockContext locks the key, blocking until it is available or the context
+// is done. It returns the context error in the latter case.
+func (k *KeyedMutex) LockContext(ctx context.Context, key string) error {
+	l := k.acquire(key)
+	if err := l.mu.LockContext(ctx); err != nil {
+		k.release(key, l)
+		return err
+	}
+
+	return nil
+}
+
+// Len returns the number of keys currently locked or waited for.
+func (k *KeyedMutex) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return len(k.locks)
+}
+
+// acquire returns the lock of the key, taking a reference to it.
+func (k *KeyedMutex) acquire(key string) *keyedLock {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.locks == nil {
+		k.locks = map[string]*keyedLock{}
+	}
+
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.refs++
+
+	return l
+}
+
+// release drops a reference to the lock of the key, removing it when unused.
+func (k *KeyedMutex) release(key string, l *keyedLock) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	l.refs--
+	if l.refs == 0 {
+		delete(k.locks, key)
+	}
+}
+
+// StripedMutex is a fixed set of mutexes that keys are spread over by hash.
+// Unlike KeyedMutex it never allocates, but unrelated keys may share
+// a mutex, so a goroutine must not hold more than one key at a time.
+type StripedMutex struct {
+	seed    maphash.Seed
+	stripes []Mutex
+}
+
+// NewStripedMutex returns a StripedMutex with n stripes.
+func NewStripedMutex(n int) *StripedMutex {
+	if n <= 0 {
+		n = 1
+	}
+
+	return &StripedMutex{
+		seed:    maphash.MakeSeed(),
+		stripes: make([]Mutex, n),
+	}
+}
+
+// Lock locks the stripe of the key.
+func (s *StripedMutex) Lock(key string) {
+	s.stripe(key).Lock()
+}
+
+// Unlock unlocks the stripe of the key.
+func (s *StripedMutex) Unlock(key string) {
+	s.stripe(key).Unlock()
+}
+
+// TryLock tries to lock the stripe of the key. It returns true in case of
+// success, false otherwise.
+func (s *StripedMutex) TryLock(key string) bool {
+	return s.stripe(key).TryLock()
+}
+
+// LockContext locks the stripe of the key, blocking until it is available
+// or the context is done. It returns the context error in the latter case.
+func (s *StripedMutex) LockContext(ctx context.Context, key string) error {
+	return s.stripe(key).LockContext(ctx)
+}
+
+func (s *StripedMutex) stripe(key string) *Mutex {
+	var h maphash.Hash
+	h.SetSeed(s.seed)
+	_, _ = h.WriteString(key)
+
+	return &s.stripes[h.Sum64()%uint64(len(s.stripes))]
+}
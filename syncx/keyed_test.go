@@ -0,0 +1,118 @@
+package syncx_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/msales/pkg/v5/syncx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyedMutex_TryLock(t *testing.T) {
+	var mu syncx.KeyedMutex
+	if !mu.TryLock("a") {
+		assert.FailNow(t, "key must be unlocked")
+	}
+	if mu.TryLock("a") {
+		assert.FailNow(t, "key must be locked")
+	}
+	if !mu.TryLock("b") {
+		assert.FailNow(t, "key must be unlocked")
+	}
+	assert.Equal(t, 2, mu.Len())
+
+	mu.Unlock("a")
+	mu.Unlock("b")
+	assert.Equal(t, 0, mu.Len())
+}
+
+func TestKeyedMutex_LockContext(t *testing.T) {
+	var mu syncx.KeyedMutex
+	mu.Lock("a")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, mu.LockContext(ctx, "a"))
+	assert.Equal(t, 1, mu.Len())
+
+	done := make(chan error)
+	go func() {
+		done <- mu.LockContext(context.Background(), "a")
+	}()
+
+	mu.Unlock("a")
+	assert.NoError(t, <-done)
+	mu.Unlock("a")
+	assert.Equal(t, 0, mu.Len())
+}
+
+func TestKeyedMutex_UnlockUnlocked(t *testing.T) {
+	var mu syncx.KeyedMutex
+
+	assert.Panics(t, func() {
+		mu.Unlock("a")
+	})
+}
+
+func TestKeyedMutex_Race(t *testing.T) {
+	var mu syncx.KeyedMutex
+	var wg sync.WaitGroup
+	counts := make([]int, 4)
+	for i := 0; i < 1024; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			n := i % len(counts)
+			key := strconv.Itoa(n)
+			mu.Lock(key)
+			counts[n]++
+			mu.Unlock(key)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, []int{256, 256, 256, 256}, counts)
+	assert.Equal(t, 0, mu.Len())
+}
+
+func TestStripedMutex(t *testing.T) {
+	mu := syncx.NewStripedMutex(16)
+	if !mu.TryLock("a") {
+		assert.FailNow(t, "key must be unlocked")
+	}
+	if mu.TryLock("a") {
+		assert.FailNow(t, "key must be locked")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, mu.LockContext(ctx, "a"))
+
+	mu.Unlock("a")
+	assert.NoError(t, mu.LockContext(context.Background(), "a"))
+	mu.Unlock("a")
+}
+
+func TestStripedMutex_Race(t *testing.T) {
+	mu := syncx.NewStripedMutex(2)
+	var wg sync.WaitGroup
+	counts := make([]int, 4)
+	for i := 0; i < 1024; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			n := i % len(counts)
+			key := strconv.Itoa(n)
+			mu.Lock(key)
+			counts[n]++
+			mu.Unlock(key)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, []int{256, 256, 256, 256}, counts)
+}
@@ -1,18 +1,16 @@
 package syncx
 
 import (
+	"context"
 	"sync"
-	"sync/atomic"
-	"unsafe"
-)
-
-const (
-	mutexLocked = 1 << iota
+	"time"
 )
 
 // Mutex is simple sync.Mutex with the ability to try to Lock.
 type Mutex struct {
 	in sync.Mutex
+
+	released notifier
 }
 
 // Lock locks m.
@@ -30,9 +28,76 @@ func (m *Mutex) Lock() {
 // arrange for another goroutine to unlock it.
 func (m *Mutex) Unlock() {
 	m.in.Unlock()
+	m.released.notify()
 }
 
 // TryLock tries to lock m. It returns true in case of success, false otherwise.
 func (m *Mutex) TryLock() bool {
-	return atomic.CompareAndSwapInt32((*int32)(unsafe.Pointer(&m.in)), 0, mutexLocked)
+	return m.in.TryLock()
+}
+
+// TryLockTimeout tries to lock m until the timeout expires. It returns true
+// in case of success, false otherwise.
+func (m *Mutex) TryLockTimeout(timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return m.LockContext(ctx) == nil
+}
+
+// LockContext locks m, blocking until the mutex is available or the context
+// is done. It returns the context error in the latter case.
+func (m *Mutex) LockContext(ctx context.Context) error {
+	return lockContext(ctx, m.in.TryLock, &m.released)
+}
+
+// lockContext calls tryLock every time the lock is released, until it
+// succeeds or the context is done.
+func lockContext(ctx context.Context, tryLock func() bool, released *notifier) error {
+	for {
+		if tryLock() {
+			return nil
+		}
+
+		ch := released.wait()
+
+		// The lock may have been released before waiting.
+		if tryLock() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ch:
+		}
+	}
+}
+
+// notifier wakes up goroutines waiting for a lock to be released.
+type notifier struct {
+	mu sync.Mutex
+	ch chan struct{}
+}
+
+// wait returns a channel that is closed on the next notify.
+func (n *notifier) wait() <-chan struct{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.ch == nil {
+		n.ch = make(chan struct{})
+	}
+
+	return n.ch
+}
+
+func (n *notifier) notify() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.ch != nil {
+		close(n.ch)
+		n.ch = nil
+	}
 }
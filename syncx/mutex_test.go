@@ -1,30 +1,15 @@
 package syncx_test
 
 import (
-	"reflect"
+	"context"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/msales/pkg/v5/syncx"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestMutexLayout(t *testing.T) {
-	sf := reflect.TypeOf((*syncx.Mutex)(nil)).Elem().FieldByIndex([]int{0, 0})
-
-	if sf.Name != "state" {
-		assert.FailNow(t, "sync.Mutex first field should have name state")
-	}
-
-	if sf.Offset != uintptr(0) {
-		assert.FailNow(t, "sync.Mutex state field should have zero offset")
-	}
-
-	if sf.Type != reflect.TypeOf(int32(1)) {
-		assert.FailNow(t, "sync.Mutex state field type should be int32")
-	}
-}
-
 func TestMutex_ImplementsLocker(t *testing.T) {
 	var mu syncx.Mutex
 
@@ -108,3 +93,71 @@ func TestMutex_Race(t *testing.T) {
 		}()
 	}
 }
+
+func TestMutex_TryLockTimeout(t *testing.T) {
+	var mu syncx.Mutex
+	if !mu.TryLockTimeout(time.Millisecond) {
+		assert.FailNow(t, "mutex must be unlocked")
+	}
+	if mu.TryLockTimeout(time.Millisecond) {
+		assert.FailNow(t, "mutex must be locked")
+	}
+
+	go func() {
+		time.Sleep(time.Millisecond)
+		mu.Unlock()
+	}()
+
+	if !mu.TryLockTimeout(time.Second) {
+		assert.FailNow(t, "mutex must be unlocked")
+	}
+	mu.Unlock()
+}
+
+func TestMutex_LockContext(t *testing.T) {
+	var mu syncx.Mutex
+	assert.NoError(t, mu.LockContext(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, mu.LockContext(ctx))
+
+	done := make(chan error)
+	go func() {
+		done <- mu.LockContext(context.Background())
+	}()
+
+	mu.Unlock()
+	assert.NoError(t, <-done)
+	mu.Unlock()
+}
+
+func TestMutex_LockContextRace(t *testing.T) {
+	var mu syncx.Mutex
+	var wg sync.WaitGroup
+	var x int
+	for i := 0; i < 1024; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			switch i % 3 {
+			case 0:
+				if !mu.TryLock() {
+					return
+				}
+			case 1:
+				mu.Lock()
+			default:
+				if err := mu.LockContext(context.Background()); err != nil {
+					return
+				}
+			}
+			x++
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.True(t, x > 0)
+}
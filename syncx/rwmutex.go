@@ -0,0 +1,75 @@
+package syncx
+
+import (
+	"context"
+	"sync"
+)
+
+// RWMutex is simple sync.RWMutex with the ability to try to Lock and RLock.
+type RWMutex struct {
+	in sync.RWMutex
+
+	released notifier
+}
+
+// Lock locks rw for writing.
+// If the lock is already locked for reading or writing,
+// Lock blocks until the lock is available.
+func (rw *RWMutex) Lock() {
+	rw.in.Lock()
+}
+
+// Unlock unlocks rw for writing.
+// It is a run-time error if rw is not locked for writing on entry to Unlock.
+func (rw *RWMutex) Unlock() {
+	rw.in.Unlock()
+	rw.released.notify()
+}
+
+// RLock locks rw for reading.
+// If the lock is already locked for writing,
+// RLock blocks until the lock is available.
+func (rw *RWMutex) RLock() {
+	rw.in.RLock()
+}
+
+// RUnlock undoes a single RLock call.
+// It is a run-time error if rw is not locked for reading on entry to RUnlock.
+func (rw *RWMutex) RUnlock() {
+	rw.in.RUnlock()
+	rw.released.notify()
+}
+
+// TryLock tries to lock rw for writing. It returns true in case of success,
+// false otherwise.
+func (rw *RWMutex) TryLock() bool {
+	return rw.in.TryLock()
+}
+
+// TryRLock tries to lock rw for reading. It returns true in case of success,
+// false otherwise.
+func (rw *RWMutex) TryRLock() bool {
+	return rw.in.TryRLock()
+}
+
+// LockContext locks rw for writing, blocking until the lock is available or
+// the context is done. It returns the context error in the latter case.
+func (rw *RWMutex) LockContext(ctx context.Context) error {
+	return lockContext(ctx, rw.in.TryLock, &rw.released)
+}
+
+// RLockContext locks rw for reading, blocking until the lock is available or
+// the context is done. It returns the context error in the latter case.
+func (rw *RWMutex) RLockContext(ctx context.Context) error {
+	return lockContext(ctx, rw.in.TryRLock, &rw.released)
+}
+
+// RLocker returns a sync.Locker that locks and unlocks rw for reading.
+func (rw *RWMutex) RLocker() sync.Locker {
+	return (*rlocker)(rw)
+}
+
+type rlocker RWMutex
+
+func (r *rlocker) Lock()   { (*RWMutex)(r).RLock() }
+func (r *rlocker) Unlock() { (*RWMutex)(r).RUnlock() }
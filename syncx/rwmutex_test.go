@@ -0,0 +1,123 @@
+package syncx_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msales/pkg/v5/syncx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRWMutex_ImplementsLocker(t *testing.T) {
+	var mu syncx.RWMutex
+
+	assert.Implements(t, (*sync.Locker)(nil), &mu)
+	assert.Implements(t, (*sync.Locker)(nil), mu.RLocker())
+}
+
+func TestRWMutex_TryLock(t *testing.T) {
+	var mu syncx.RWMutex
+	if !mu.TryLock() {
+		assert.FailNow(t, "mutex must be unlocked")
+	}
+	if mu.TryLock() {
+		assert.FailNow(t, "mutex must be locked")
+	}
+	if mu.TryRLock() {
+		assert.FailNow(t, "mutex must be locked")
+	}
+
+	mu.Unlock()
+	if !mu.TryRLock() {
+		assert.FailNow(t, "mutex must be unlocked for reading")
+	}
+	if !mu.TryRLock() {
+		assert.FailNow(t, "mutex must be unlocked for reading")
+	}
+	if mu.TryLock() {
+		assert.FailNow(t, "mutex must be locked for reading")
+	}
+
+	mu.RUnlock()
+	mu.RUnlock()
+	if !mu.TryLock() {
+		assert.FailNow(t, "mutex must be unlocked")
+	}
+	mu.Unlock()
+}
+
+func TestRWMutex_LockContext(t *testing.T) {
+	var mu syncx.RWMutex
+	mu.RLock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, mu.LockContext(ctx))
+
+	done := make(chan error)
+	go func() {
+		done <- mu.LockContext(context.Background())
+	}()
+
+	mu.RUnlock()
+	assert.NoError(t, <-done)
+	mu.Unlock()
+}
+
+func TestRWMutex_RLockContext(t *testing.T) {
+	var mu syncx.RWMutex
+	mu.Lock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, mu.RLockContext(ctx))
+
+	done := make(chan error)
+	go func() {
+		done <- mu.RLockContext(context.Background())
+	}()
+
+	mu.Unlock()
+	assert.NoError(t, <-done)
+	assert.NoError(t, mu.RLockContext(context.Background()))
+	mu.RUnlock()
+	mu.RUnlock()
+}
+
+func TestRWMutex_Race(t *testing.T) {
+	var mu syncx.RWMutex
+	var wg sync.WaitGroup
+	var x int
+	for i := 0; i < 1024; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			switch i % 4 {
+			case 0:
+				if mu.TryLock() {
+					x++
+					mu.Unlock()
+				}
+			case 1:
+				if err := mu.LockContext(context.Background()); err == nil {
+					x++
+					mu.Unlock()
+				}
+			case 2:
+				if mu.TryRLock() {
+					_ = x
+					mu.RUnlock()
+				}
+			default:
+				if err := mu.RLockContext(context.Background()); err == nil {
+					_ = x
+					mu.RUnlock()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}